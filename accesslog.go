@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Supported values for Config.AccessLogFormat
+const (
+	AccessLogStructured = "structured" // only the structured http request entries
+	AccessLogCommon     = "common"     // NCSA Common Log Format
+	AccessLogCombined   = "combined"   // NCSA Combined Log Format, common plus referrer and user agent
+)
+
+// clfTime is the timestamp layout of the NCSA formats
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+var accessPool = buffer.NewPool()
+
+// globalAccess is the access log file of the global logger, nil unless
+// AccessLogFormat is common or combined
+var globalAccess zapcore.WriteSyncer
+
+// writeAccessLine writes the NCSA line of a request served by Middleware to
+// the access log file, when one is configured
+func writeAccessLine(r *http.Request, rec *statusRecorder, start time.Time) {
+	access, format := globalAccess, globalConfig.AccessLogFormat
+	if access == nil {
+		return
+	}
+
+	buf := accessPool.Get()
+	defer buf.Free()
+	appendAccessLine(buf, format, r, rec.status, rec.size, start)
+	_, _ = access.Write(buf.Bytes())
+}
+
+// appendAccessLine appends a line in the common or combined format, with "-"
+// for missing values:
+//
+//	host ident authuser [date] "request" status bytes "referrer" "user-agent"
+func appendAccessLine(buf *buffer.Buffer, format string, r *http.Request, status, size int, start time.Time) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	appendCLFValue(buf, host)
+	buf.AppendString(" - ")
+	appendCLFValue(buf, requestUser(r))
+	buf.AppendString(" [")
+	buf.AppendString(start.Format(clfTime))
+	buf.AppendString("] \"")
+
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	appendCLFEscaped(buf, r.Method+" "+uri+" "+r.Proto)
+	buf.AppendString("\" ")
+	buf.AppendInt(int64(status))
+	buf.AppendByte(' ')
+	if size > 0 {
+		buf.AppendInt(int64(size))
+	} else {
+		buf.AppendByte('-')
+	}
+
+	if format == AccessLogCombined {
+		buf.AppendString(" \"")
+		appendCLFValue(buf, r.Referer())
+		buf.AppendString("\" \"")
+		appendCLFValue(buf, r.UserAgent())
+		buf.AppendByte('"')
+	}
+	buf.AppendByte('\n')
+}
+
+// requestUser returns the basic auth or URL user name of r
+func requestUser(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok {
+		return user
+	}
+	if r.URL != nil && r.URL.User != nil {
+		return r.URL.User.Username()
+	}
+	return ""
+}
+
+// appendCLFValue appends an escaped value, "-" when empty
+func appendCLFValue(buf *buffer.Buffer, s string) {
+	if s == "" {
+		buf.AppendByte('-')
+		return
+	}
+	appendCLFEscaped(buf, s)
+}
+
+// appendCLFEscaped appends s with quotes and backslashes escaped and
+// non-printable bytes as \xhh, like Apache does
+func appendCLFEscaped(buf *buffer.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			buf.AppendByte('\\')
+			buf.AppendByte(c)
+		case c < 0x20 || c >= 0x7f:
+			buf.AppendString(`\x`)
+			if c < 0x10 {
+				buf.AppendByte('0')
+			}
+			buf.AppendString(strconv.FormatUint(uint64(c), 16))
+		default:
+			buf.AppendByte(c)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"encoding/base64"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultMaxBinaryBytes = 1024
+
+// binaryValue is b logged as an object with its base64 data and full length
+type binaryValue struct {
+	data      []byte
+	size      int
+	truncated bool
+}
+
+// Binary returns a field logging b as an object with the base64 encoded data,
+// the original size in bytes and whether the data was cut to
+// Config.MaxBinaryBytes. It can be passed as a key-value pair to the
+// structured functions:
+//
+//	logger.DebugStruct("decoded request", logger.Binary("payload", raw))
+//
+// logs payload={"base64":"...","bytes":48210,"truncated":true}.
+func Binary(key string, b []byte) zap.Field {
+	limit := globalConfig.MaxBinaryBytes
+	if limit <= 0 {
+		limit = defaultMaxBinaryBytes
+	}
+	v := binaryValue{data: b, size: len(b)}
+	if len(b) > limit {
+		v.data, v.truncated = b[:limit], true
+	}
+	return zap.Object(key, v)
+}
+
+func (v binaryValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("base64", base64.StdEncoding.EncodeToString(v.data))
+	enc.AddInt("bytes", v.size)
+	enc.AddBool("truncated", v.truncated)
+	return nil
+}
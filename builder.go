@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Builder accumulates fields for a single log entry:
+//
+//	logger.Field("user", id).Field("action", a).Info("done")
+//
+// Builders are pooled; a builder must not be used after its terminal
+// Debug/Info/Warn/Error call.
+type Builder struct {
+	ctx context.Context
+	kv  []interface{}
+}
+
+var builderPool = sync.Pool{
+	New: func() interface{} {
+		return &Builder{kv: make([]interface{}, 0, 8)}
+	},
+}
+
+func newBuilder() *Builder {
+	return builderPool.Get().(*Builder)
+}
+
+// Field starts a builder with one field
+func Field(key string, value interface{}) *Builder {
+	return newBuilder().Field(key, value)
+}
+
+// CtxBuilder starts a builder that also attaches the context fields of ctx
+func CtxBuilder(ctx context.Context) *Builder {
+	return newBuilder().Ctx(ctx)
+}
+
+// Field adds a field to the entry
+func (b *Builder) Field(key string, value interface{}) *Builder {
+	b.kv = append(b.kv, key, value)
+	return b
+}
+
+// Ctx attaches the context fields of ctx, as the Ctx logging functions do
+func (b *Builder) Ctx(ctx context.Context) *Builder {
+	b.ctx = ctx
+	return b
+}
+
+func (b *Builder) logger() *zap.SugaredLogger {
+	if b.ctx != nil {
+		return ctxLogger(b.ctx)
+	}
+	ensureInitialized()
+	return globalLogger
+}
+
+func (b *Builder) release() {
+	for i := range b.kv {
+		b.kv[i] = nil
+	}
+	b.kv = b.kv[:0]
+	b.ctx = nil
+	builderPool.Put(b)
+}
+
+// Terminal functions emit the entry and return the builder to the pool
+func (b *Builder) Debug(msg string) {
+	b.logger().Debugw(msg, b.kv...)
+	b.release()
+}
+
+func (b *Builder) Info(msg string) {
+	b.logger().Infow(msg, b.kv...)
+	b.release()
+}
+
+func (b *Builder) Warn(msg string) {
+	b.logger().Warnw(msg, b.kv...)
+	b.release()
+}
+
+func (b *Builder) Error(msg string) {
+	b.logger().Errorw(msg, b.kv...)
+	b.release()
+}
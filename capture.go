@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// captureMu serializes Capture calls
+var captureMu sync.Mutex
+
+// globalPipeline is the pipeline of the global logger, nil when it was not
+// built by Init
+var globalPipeline *pipeline
+
+// captureTarget is the destination of a running Capture
+type captureTarget struct {
+	core zapcore.Core // w's core behind the global level, without fields
+}
+
+// activeCapture is the target of the running Capture, nil outside of one
+var activeCapture atomic.Pointer[captureTarget]
+
+// Capture runs fn with all output of the global and audit loggers going to w
+// instead of the configured sinks, encoded like the log file with the same
+// initial fields, level and hooks. The sinks are restored when fn returns or
+// panics. Concurrent calls run one after another, and goroutines logging
+// meanwhile are captured as well. Loggers derived from the global loggers,
+// e.g. with WithFields, are captured too, WithService loggers are not.
+func Capture(w io.Writer, fn func()) {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	ensureInitialized()
+
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	if p := globalPipeline; p != nil {
+		enc = p.newEncoder()
+	}
+	ws := zapcore.Lock(zapcore.AddSync(w))
+	activeCapture.Store(&captureTarget{core: newLevelCore(zapcore.NewCore(enc, ws, allLevels), globalLevel)})
+	defer activeCapture.Store(nil)
+	fn()
+}
+
+// capturable wraps the core of l so Capture can redirect its entries. fields
+// are the initial fields l's core already carries, they are added to the
+// captured entries. With registry the captured entries run through the hooks
+// and filters like those of the global logger.
+func capturable(l *zap.Logger, fields []zap.Field, registry bool) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &captureCore{Core: core, fields: fields, registry: registry}
+	}))
+}
+
+// captureCore passes entries to the core of the running Capture instead of
+// the wrapped core, with the context fields added so far
+type captureCore struct {
+	zapcore.Core
+	fields   []zapcore.Field
+	registry bool
+	cached   atomic.Pointer[capturedCore]
+}
+
+// capturedCore is the capture core built for a target
+type capturedCore struct {
+	target *captureTarget
+	core   zapcore.Core
+}
+
+// captured returns the core of the running Capture, nil outside of one
+func (c *captureCore) captured() zapcore.Core {
+	t := activeCapture.Load()
+	if t == nil {
+		return nil
+	}
+	if cached := c.cached.Load(); cached != nil && cached.target == t {
+		return cached.core
+	}
+	core := t.core
+	if c.registry {
+		core = registryCore(core)
+	}
+	core = core.With(c.fields)
+	c.cached.Store(&capturedCore{target: t, core: core})
+	return core
+}
+
+func (c *captureCore) Enabled(l zapcore.Level) bool {
+	if core := c.captured(); core != nil {
+		return core.Enabled(l)
+	}
+	return c.Core.Enabled(l)
+}
+
+func (c *captureCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return &captureCore{Core: c.Core.With(fields), fields: append(merged, fields...), registry: c.registry}
+}
+
+func (c *captureCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core := c.captured(); core != nil {
+		return core.Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *captureCore) Sync() error {
+	if core := c.captured(); core != nil {
+		return core.Sync()
+	}
+	return c.Core.Sync()
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCaptureRedirectsGlobalLoggers(t *testing.T) {
+	defer Snapshot()()
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := Init(Config{ServiceName: "checkout", LogFile: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+	derived := WithFields("order_id", 7)
+
+	var out bytes.Buffer
+	Capture(&out, func() {
+		Info("captured entry")
+		derived.Info("captured derived entry")
+		Audit("captured audit entry")
+	})
+	Info("after capture")
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	captured := out.String()
+	for _, want := range []string{`"message":"captured entry"`, `"message":"captured derived entry"`, `"order_id":7`, `"message":"captured audit entry"`, `"service":"checkout"`} {
+		if !strings.Contains(captured, want) {
+			t.Errorf("captured output lacks %s:\n%s", want, captured)
+		}
+	}
+	if strings.Contains(captured, "after capture") {
+		t.Errorf("entry after Capture returned was captured:\n%s", captured)
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(file), "captured") {
+		t.Errorf("captured entries reached the log file:\n%s", file)
+	}
+	if !strings.Contains(string(file), "after capture") {
+		t.Errorf("entry after Capture missing from the log file:\n%s", file)
+	}
+}
+
+// Run with -race: Capture must not swap loggers other goroutines read
+func TestCaptureConcurrentLogging(t *testing.T) {
+	defer Snapshot()()
+	if err := Init(Config{ServiceName: "checkout", LogFile: filepath.Join(t.TempDir(), "app.log")}); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Info("background entry")
+					WithFields("worker", 1).Warn("background warning")
+					Audit("background audit")
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		var out bytes.Buffer
+		Capture(&out, func() { Info("captured entry") })
+		if !strings.Contains(out.String(), "captured entry") {
+			t.Errorf("capture %d lacks its entry:\n%s", i, out.String())
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
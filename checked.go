@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// LogChecked logs at level and reports whether the entry passed the level
+// and sampling gates and was handed to the sinks, so callers can skip work
+// tied to a dropped entry or count sampling drops. Filters, SetLevelForField
+// overrides set on entry fields and pressure shedding decide after that point
+// and are not reflected in the result.
+func LogChecked(level zapcore.Level, msg string, keysAndValues ...interface{}) bool {
+	ensureInitialized()
+
+	ce := globalLogger.Desugar().Check(level, msg)
+	if ce == nil {
+		return false
+	}
+	ce.Write(toFields(keysAndValues)...)
+	return true
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the time source of entry timestamps and the time-based features:
+// console rate limiting, CollapseRepeats, write buffer flushing and the
+// WriteTimeout watchdog. Its method set matches zapcore.Clock plus After.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) *time.Ticker
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+type clockBox struct{ Clock }
+
+var currentClock atomic.Pointer[clockBox]
+
+// SetClock replaces the clock, nil restores the system clock. It takes effect
+// immediately, also for loggers built earlier, except for the flush ticker of
+// an existing write buffer. A fake clock can drive tickers through the C field
+// of a &time.Ticker{C: ch} it returns.
+//
+// Meant for tests of time-dependent logging, not for production use.
+func SetClock(c Clock) {
+	if c == nil {
+		currentClock.Store(nil)
+		return
+	}
+	currentClock.Store(&clockBox{c})
+}
+
+func clock() Clock {
+	if b := currentClock.Load(); b != nil {
+		return b.Clock
+	}
+	return systemClock{}
+}
+
+// globalClock defers to the clock set with SetClock, for components such as
+// zap and BufferedWriteSyncer that keep the clock they were built with
+type globalClock struct{}
+
+func (globalClock) Now() time.Time                         { return clock().Now() }
+func (globalClock) After(d time.Duration) <-chan time.Time { return clock().After(d) }
+func (globalClock) NewTicker(d time.Duration) *time.Ticker { return clock().NewTicker(d) }
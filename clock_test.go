@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock for tests: Now only moves with Add, and tickers fire
+// when the test sends on the channel returned by Ticker
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []fakeTicker
+}
+
+type fakeTicker struct {
+	d time.Duration
+	c chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time { return make(chan time.Time) }
+
+func (c *fakeClock) NewTicker(d time.Duration) *time.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time)
+	c.tickers = append(c.tickers, fakeTicker{d: d, c: ch})
+	return &time.Ticker{C: ch}
+}
+
+// Ticker returns the interval and the channel of the i-th ticker created, ok
+// is false while it does not exist yet
+func (c *fakeClock) Ticker(i int) (d time.Duration, ch chan<- time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if i >= len(c.tickers) {
+		return 0, nil, false
+	}
+	return c.tickers[i].d, c.tickers[i].c, true
+}
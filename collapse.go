@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// collapseFlush is how long repeats are held back before a summary is written
+// even if no different entry arrives
+const collapseFlush = 10 * time.Second
+
+// repeatState is the last entry of a pipeline and how often it repeated since
+type repeatState struct {
+	mu      sync.Mutex
+	key     uint64
+	level   zapcore.Level
+	message string
+	count   int
+	core    zapcore.Core  // core of the last entry, with its context fields
+	stop    chan struct{} // cancels the pending flush, nil when none is pending
+}
+
+// collapseCore collapses back-to-back identical entries, same level, message
+// and fields, into one "last message repeated N times" line, written when a
+// different entry arrives, after collapseFlush or on Sync.
+type collapseCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+	state  *repeatState
+}
+
+func newCollapseCore(core zapcore.Core, enabled bool) zapcore.Core {
+	if !enabled {
+		return core
+	}
+	return &collapseCore{Core: core, state: &repeatState{}}
+}
+
+func (c *collapseCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return &collapseCore{Core: c.Core.With(fields), fields: append(merged, fields...), state: c.state}
+}
+
+func (c *collapseCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return deferredWrite(c.Core, ent, ce, c.hold)
+}
+
+func (c *collapseCore) Sync() error {
+	c.flush()
+	return c.Core.Sync()
+}
+
+// hold holds back an entry repeating the previous one, any other entry is
+// written after the summary of the repeats before it
+func (c *collapseCore) hold(ent zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+	s := c.state
+	key := entryHash(ent, c.fields, fields)
+
+	s.mu.Lock()
+	if s.core != nil && key == s.key && s.message == ent.Message {
+		s.count++
+		if s.stop == nil {
+			s.stop = make(chan struct{})
+			go c.flushAfter(clock().After(collapseFlush), s.stop)
+		}
+		s.mu.Unlock()
+		return nil, false
+	}
+	c.summarizeLocked(ent.Time)
+	s.key, s.level, s.message, s.count, s.core = key, ent.Level, ent.Message, 0, c.Core
+	s.mu.Unlock()
+	return fields, true
+}
+
+// flushAfter flushes once timeout fires unless stop is closed first
+func (c *collapseCore) flushAfter(timeout <-chan time.Time, stop <-chan struct{}) {
+	select {
+	case <-timeout:
+		c.flush()
+	case <-stop:
+	}
+}
+
+// flush writes the summary for repeats held back so far
+func (c *collapseCore) flush() {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	c.summarizeLocked(clock().Now())
+}
+
+// summarizeLocked writes the pending summary; the caller holds state.mu
+func (c *collapseCore) summarizeLocked(t time.Time) {
+	s := c.state
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	if s.count == 0 {
+		return
+	}
+	ent := zapcore.Entry{
+		Level:   s.level,
+		Time:    t,
+		Message: "last message repeated " + strconv.Itoa(s.count) + " times",
+	}
+	// Written with the context fields of the repeated entry, checked so the
+	// level of every sink applies
+	if ce := s.core.Check(ent, nil); ce != nil {
+		ce.Write(
+			zap.String("repeated_message", s.message),
+			zap.Int("repeat_count", s.count),
+		)
+	}
+	s.count = 0
+}
+
+// entryHash identifies an entry by level, message and fields
+func entryHash(ent zapcore.Entry, sets ...[]zapcore.Field) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%s\x00", ent.Level, ent.Message)
+	for _, fields := range sets {
+		for _, f := range fields {
+			fmt.Fprintf(h, "%s\x00%d\x00%d\x00%s\x00%v\x00", f.Key, f.Type, f.Integer, f.String, f.Interface)
+		}
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readEntries decodes the JSON lines of a log file
+func readEntries(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("decoding %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestCollapseRepeatsWithSequenceAndTTL(t *testing.T) {
+	defer Snapshot()()
+	path := filepath.Join(t.TempDir(), "app.log")
+	err := Init(Config{
+		ServiceName:     "test",
+		LogFile:         path,
+		CollapseRepeats: true,
+		IncludeSequence: true,
+		DefaultTTL:      time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	Info("x")
+	Info("x")
+	Info("x")
+	Info("y")
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, path)
+	want := []struct {
+		message string
+		seq     float64
+	}{
+		{"x", 1},
+		{"last message repeated 2 times", 2},
+		{"y", 3},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("wrote %d entries, want %d: %v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e["message"] != w.message || e["seq"] != w.seq {
+			t.Errorf("entry %d = %v %v, want %q with seq %v", i, e["message"], e["seq"], w.message, w.seq)
+		}
+		if e["ttl_seconds"] != float64(3600) {
+			t.Errorf("entry %d ttl_seconds = %v, want 3600", i, e["ttl_seconds"])
+		}
+	}
+	if entries[1]["repeated_message"] != "x" || entries[1]["repeat_count"] != float64(2) {
+		t.Errorf("summary = %v, want 2 repeats of x", entries[1])
+	}
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var compactPool = buffer.NewPool()
+
+// compactEncoder writes one grep-friendly line per entry:
+//
+//	2006-01-02T15:04:05Z INFO [name] message key=value nested.key=value
+//
+// Fields are recorded like in sortedJSONEncoder and rendered in key order;
+// objects and namespaces are flattened into dotted keys. Caller and stacktrace
+// are never written.
+type compactEncoder struct {
+	*sortedJSONEncoder
+	levelEncoding string // Config.LevelEncoding, upper case when unset
+	color         bool   // Config.ConsoleColor
+}
+
+func newCompactEncoder(levelEncoding string, color bool) compactEncoder {
+	if levelEncoding == "" {
+		levelEncoding = LevelEncodingUpper
+	}
+	return compactEncoder{&sortedJSONEncoder{root: &sortedNode{}}, levelEncoding, color}
+}
+
+func (e compactEncoder) Clone() zapcore.Encoder {
+	return compactEncoder{e.sortedJSONEncoder.Clone().(*sortedJSONEncoder), e.levelEncoding, e.color}
+}
+
+func (e compactEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(compactEncoder)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	values := zapcore.NewMapObjectEncoder()
+	replaySorted(values, final.root)
+
+	buf := compactPool.Get()
+	buf.AppendString(ent.Time.UTC().Format(time.RFC3339))
+	buf.AppendByte(' ')
+	if e.color {
+		buf.AppendString(levelColor(ent.Level))
+		buf.AppendString(levelText(ent.Level, e.levelEncoding))
+		buf.AppendString(colorReset)
+	} else {
+		buf.AppendString(levelText(ent.Level, e.levelEncoding))
+	}
+	if ent.LoggerName != "" {
+		buf.AppendString(" [")
+		buf.AppendString(ent.LoggerName)
+		buf.AppendByte(']')
+	}
+	buf.AppendByte(' ')
+	buf.AppendString(ent.Message)
+	appendCompactFields(buf, "", values.Fields)
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+const colorReset = "\x1b[0m"
+
+// levelColor returns the ANSI color of a level, as used by zap's color encoders
+func levelColor(l zapcore.Level) string {
+	switch {
+	case l < zapcore.InfoLevel:
+		return "\x1b[35m" // magenta
+	case l == zapcore.InfoLevel:
+		return "\x1b[34m" // blue
+	case l == zapcore.WarnLevel:
+		return "\x1b[33m" // yellow
+	default:
+		return "\x1b[31m" // red
+	}
+}
+
+func appendCompactFields(buf *buffer.Buffer, prefix string, fields map[string]interface{}) {
+	for _, k := range sortedKeys(fields) {
+		if nested, ok := fields[k].(map[string]interface{}); ok {
+			appendCompactFields(buf, prefix+k+".", nested)
+			continue
+		}
+		buf.AppendByte(' ')
+		buf.AppendString(prefix + k)
+		buf.AppendByte('=')
+		buf.AppendString(compactValue(fields[k]))
+	}
+}
+
+// compactValue formats a field value, quoting strings that would break k=v parsing
+func compactValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, " =\"\t\n\r") || !strconv.CanBackquote(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration, bool, fmt.Stringer,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr,
+		float32, float64, complex64, complex128:
+		return fmt.Sprint(v)
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
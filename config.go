@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// EffectiveConfig returns the fully resolved configuration of the global logger,
+// after environment defaults have been applied. It is empty when the global
+// logger fell back to the console-only default.
+func EffectiveConfig() Config {
+	cfg := globalConfig
+	cfg.AdditionalFields = copyFields(cfg.AdditionalFields)
+	cfg.EnvelopeFields = copyFields(cfg.EnvelopeFields)
+	return cfg
+}
+
+func copyFields(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		fields[k] = v
+	}
+	return fields
+}
+
+// DumpConfig writes the effective configuration to w as indented JSON.
+// Nothing is redacted since Config holds no secrets; credentials such as a
+// DSN or token added to Config later must be redacted here.
+func DumpConfig(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(EffectiveConfig())
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultConsoleErrorThreshold = 10
+
+// consoleGuard is the failure state of one console stream, shared by the
+// cores derived from it
+type consoleGuard struct {
+	stream    string
+	threshold int64
+	failures  atomic.Int64 // consecutive failed writes
+	disabled  atomic.Bool
+}
+
+// consoleGuardCore stops writing to a console stream that keeps failing,
+// e.g. a closed stdout of a daemon, so zap doesn't report every entry
+type consoleGuardCore struct {
+	zapcore.Core
+	guard *consoleGuard
+}
+
+func newConsoleGuardCore(core zapcore.Core, stream string, threshold int) zapcore.Core {
+	if threshold < 0 {
+		return core
+	}
+	if threshold == 0 {
+		threshold = defaultConsoleErrorThreshold
+	}
+	return &consoleGuardCore{Core: core, guard: &consoleGuard{stream: stream, threshold: int64(threshold)}}
+}
+
+func (c *consoleGuardCore) With(fields []zapcore.Field) zapcore.Core {
+	return &consoleGuardCore{Core: c.Core.With(fields), guard: c.guard}
+}
+
+func (c *consoleGuardCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.guard.disabled.Load() && c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *consoleGuardCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if err == nil {
+		c.guard.failures.Store(0)
+		return nil
+	}
+	if c.guard.failures.Add(1) == c.guard.threshold && !c.guard.disabled.Swap(true) {
+		// The console is skipped from now on, so this reaches the other sinks only
+		InternalLogger().Desugar().WithOptions(zap.WithCaller(false)).Warn("console output disabled after repeated write errors",
+			zap.String("stream", c.guard.stream),
+			zap.Int64("failed_writes", c.guard.threshold),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+func (c *consoleGuardCore) Sync() error {
+	if c.guard.disabled.Load() {
+		return nil
+	}
+	return c.Core.Sync()
+}
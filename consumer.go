@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ConsumeWithLogging runs a message queue handler with request-style logging.
+// The handler's context carries message_id and a trace_id: the one already in
+// ctx if any, otherwise the message ID, or a generated one when msgID is
+// empty. Start is logged at Debug, success at Info and failure at Error, with
+// the duration. A panic in handler is logged with its stack and returned as an
+// error, so one bad message doesn't take the consumer down.
+func ConsumeWithLogging(ctx context.Context, msgID string, handler func(context.Context) error) (err error) {
+	ensureInitialized()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	b := Baggage{}
+	if BaggageFromContext(ctx).TraceID == "" {
+		b.TraceID = msgID
+		if b.TraceID == "" {
+			b.TraceID = generateTraceID(globalConfig.IDFormat)
+		}
+	}
+	if msgID != "" {
+		b.Extra = map[string]string{"message_id": msgID}
+	}
+	ctx = ContextWithBaggage(ctx, b)
+
+	// Called directly rather than through a package function, hence the skip
+	l := ctxLogger(ctx).WithOptions(zap.AddCallerSkip(-1))
+	start := time.Now()
+	l.Debugw("message received")
+
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(ctx, r, debug.Stack())
+			err = fmt.Errorf("panic handling message %q: %v", msgID, r)
+		}
+		if err != nil {
+			l.Errorw("message failed", "duration", time.Since(start), "error", err.Error())
+			return
+		}
+		l.Infow("message processed", "duration", time.Since(start))
+	}()
+	return handler(ctx)
+}
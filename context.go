@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Baggage holds the correlation fields carried through a context.
+// Empty fields are not logged.
+type Baggage struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	RequestID    string
+	TenantID     string
+	UserID       string
+	Extra        map[string]string // Additional correlation fields, logged under their own keys
+}
+
+type baggageKey struct{}
+
+// ContextWithBaggage returns a context carrying b merged over any baggage already
+// in ctx. Non-empty fields of b override existing values.
+func ContextWithBaggage(ctx context.Context, b Baggage) context.Context {
+	merged := BaggageFromContext(ctx)
+	if b.TraceID != "" {
+		merged.TraceID = b.TraceID
+	}
+	if b.SpanID != "" {
+		merged.SpanID = b.SpanID
+	}
+	if b.ParentSpanID != "" {
+		merged.ParentSpanID = b.ParentSpanID
+	}
+	if b.RequestID != "" {
+		merged.RequestID = b.RequestID
+	}
+	if b.TenantID != "" {
+		merged.TenantID = b.TenantID
+	}
+	if b.UserID != "" {
+		merged.UserID = b.UserID
+	}
+	if len(b.Extra) > 0 {
+		extra := make(map[string]string, len(merged.Extra)+len(b.Extra))
+		for k, v := range merged.Extra {
+			extra[k] = v
+		}
+		for k, v := range b.Extra {
+			extra[k] = v
+		}
+		merged.Extra = extra
+	}
+	return context.WithValue(ctx, baggageKey{}, merged)
+}
+
+// BaggageFromContext returns the baggage carried by ctx, or an empty Baggage
+func BaggageFromContext(ctx context.Context) Baggage {
+	if ctx == nil {
+		return Baggage{}
+	}
+	b, _ := ctx.Value(baggageKey{}).(Baggage)
+	return b
+}
+
+// keysAndValues returns the set baggage fields as key-value pairs
+func (b Baggage) keysAndValues() []interface{} {
+	var kv []interface{}
+	add := func(key, value string) {
+		if value != "" {
+			kv = append(kv, key, value)
+		}
+	}
+	if b.TraceID != "" {
+		key, value := traceField(globalConfig, b.TraceID)
+		kv = append(kv, key, value)
+	}
+	add("span_id", b.SpanID)
+	add("parent_span_id", b.ParentSpanID)
+	add("request_id", b.RequestID)
+	add("tenant_id", b.TenantID)
+	add("user_id", b.UserID)
+
+	keys := make([]string, 0, len(b.Extra))
+	for k := range b.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add(k, b.Extra[k])
+	}
+	return kv
+}
+
+// contextField maps a context key to the field its value is logged under
+type contextField struct {
+	key    string
+	ctxKey interface{}
+}
+
+var (
+	contextFieldsMu sync.RWMutex
+	registeredCtx   []contextField
+)
+
+// RegisterContextField makes the Ctx logging functions log ctx.Value(ctxKey)
+// under key. Registered fields follow the baggage fields in registration
+// order; registering a key again replaces its context key in place. Contexts
+// without a value for ctxKey are skipped silently.
+func RegisterContextField(key string, ctxKey interface{}) {
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+
+	// Copy on write, readers keep using the slice they grabbed
+	updated := make([]contextField, 0, len(registeredCtx)+1)
+	replaced := false
+	for _, f := range registeredCtx {
+		if f.key == key {
+			f.ctxKey = ctxKey
+			replaced = true
+		}
+		updated = append(updated, f)
+	}
+	if !replaced {
+		updated = append(updated, contextField{key: key, ctxKey: ctxKey})
+	}
+	registeredCtx = updated
+}
+
+// contextFields returns the key-value pairs the Ctx logging functions attach for ctx
+func contextFields(ctx context.Context) []interface{} {
+	kv := BaggageFromContext(ctx).keysAndValues()
+
+	if ctx != nil {
+		contextFieldsMu.RLock()
+		registered := registeredCtx
+		contextFieldsMu.RUnlock()
+		for _, f := range registered {
+			if v := ctx.Value(f.ctxKey); v != nil {
+				kv = append(kv, f.key, v)
+			}
+		}
+	}
+
+	// Whether the entry belongs to a trace kept by the tracing backend
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			kv = append(kv, "trace_sampled", sc.IsSampled())
+		}
+	}
+	return kv
+}
+
+// ContextFields returns the fields the Ctx logging functions would attach for
+// ctx: its baggage, registered context fields and the trace sampling flag. It
+// is read-only introspection for debug endpoints and tests; fields added with
+// With on a derived logger are not part of the context and not included.
+func ContextFields(ctx context.Context) map[string]interface{} {
+	kv := contextFields(ctx)
+	fields := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fields[kv[i].(string)] = kv[i+1]
+	}
+	return fields
+}
+
+// globalTraceless is the global logger without the session trace ID, for
+// contexts with a trace ID of their own; nil when there is no session trace ID
+// or the global logger was not built by Init
+var globalTraceless *zap.SugaredLogger
+
+// ctxLogger returns the global logger with the context fields attached
+func ctxLogger(ctx context.Context) *zap.SugaredLogger {
+	ensureInitialized()
+
+	kv := ctxLoggerFields(ctx)
+	if len(kv) == 0 {
+		return globalLogger
+	}
+	// A trace ID from ctx replaces the session one rather than logging the key twice
+	if globalTraceless != nil && BaggageFromContext(ctx).TraceID != "" {
+		return globalTraceless.With(kv...)
+	}
+	return globalLogger.With(kv...)
+}
+
+// ctxLoggerFields returns the context fields plus the ContextWithLevel level
+// of ctx, for loggers bound to the context
+func ctxLoggerFields(ctx context.Context) []interface{} {
+	kv := contextFields(ctx)
+	if f, ok := contextLevelField(ctx); ok {
+		kv = append(kv, f)
+	}
+	return kv
+}
+
+// Context-aware structured logging functions
+func InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	ctxLogger(ctx).Infow(msg, keysAndValues...)
+}
+
+func ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	ctxLogger(ctx).Errorw(msg, keysAndValues...)
+}
+
+func WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	ctxLogger(ctx).Warnw(msg, keysAndValues...)
+}
+
+func DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	ctxLogger(ctx).Debugw(msg, keysAndValues...)
+}
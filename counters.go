@@ -0,0 +1,38 @@
+package logger
+
+// Log-based metric fields, a stable schema for aggregating entries of Count
+// and Gauge in the log pipeline:
+//
+//	message       "metric"
+//	metric_type   "count" or "gauge"
+//	metric_name   the name passed in
+//	metric_value  1 for counts, the sampled value for gauges
+//
+// Additional key-value pairs are logged alongside and can serve as
+// dimensions. Entries are logged at Info.
+const (
+	metricMessage   = "metric"
+	metricTypeKey   = "metric_type"
+	metricNameKey   = "metric_name"
+	metricValueKey  = "metric_value"
+	metricTypeCount = "count"
+	metricTypeGauge = "gauge"
+)
+
+// Count logs one occurrence of the event name, see the metric field schema above
+func Count(name string, keysAndValues ...interface{}) {
+	ensureInitialized()
+	globalLogger.Infow(metricMessage, metricFields(metricTypeCount, name, 1, keysAndValues)...)
+}
+
+// Gauge logs a sample of the gauge name, see the metric field schema above
+func Gauge(name string, value float64, keysAndValues ...interface{}) {
+	ensureInitialized()
+	globalLogger.Infow(metricMessage, metricFields(metricTypeGauge, name, value, keysAndValues)...)
+}
+
+func metricFields(kind, name string, value interface{}, keysAndValues []interface{}) []interface{} {
+	kv := make([]interface{}, 0, 6+len(keysAndValues))
+	kv = append(kv, metricTypeKey, kind, metricNameKey, name, metricValueKey, value)
+	return append(kv, keysAndValues...)
+}
@@ -0,0 +1,22 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+)
+
+// globalCritical logs to the sinks of the global logger past sampling,
+// replaced on every Init
+var globalCritical *zap.SugaredLogger
+
+// Critical returns a logger for components such as billing or auth whose
+// entries must never be dropped by sampling. It bypasses Config.Sampling and
+// CollapseRepeats but otherwise behaves like the global logger: the global,
+// named and context levels still gate it, and filters, hooks and registered
+// writers still apply. Unlike Audit it writes nothing to AuditLogFile.
+//
+//	billing := logger.Critical().Named("billing")
+func Critical() *zap.SugaredLogger {
+	ensureInitialized()
+	// Returned for direct use, so drop the skip meant for the package functions
+	return globalCritical.WithOptions(zap.AddCallerSkip(-1))
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceLevel is finer than Debug; enable it with SetLevel(TraceLevel)
+const TraceLevel = zapcore.DebugLevel - 1
+
+// levelAliasKey marks entries logged under an alias of a zap level, see Notice
+const levelAliasKey = "level_alias"
+
+var (
+	levelNamesMu sync.RWMutex
+	levelNames   = map[zapcore.Level]string{TraceLevel: "trace"}
+)
+
+// RegisterLevelName sets the name written for level, e.g. to print Warn as
+// "warning" or to name levels below TraceLevel. It applies to loggers built
+// afterwards as well as existing ones; Cloud Logging format keeps its severities.
+func RegisterLevelName(level zapcore.Level, name string) {
+	levelNamesMu.Lock()
+	defer levelNamesMu.Unlock()
+	levelNames[level] = name
+}
+
+// Supported values for Config.LevelEncoding
+const (
+	LevelEncodingLower   = "lower"   // "info"
+	LevelEncodingUpper   = "upper"   // "INFO"
+	LevelEncodingCapital = "capital" // "INFO", zap's name for upper case
+	LevelEncodingNumber  = "number"  // 0, zap's numeric level
+)
+
+// levelEncoder encodes levels per Config.LevelEncoding, lower case by default,
+// using registered level names
+func levelEncoder(encoding string) zapcore.LevelEncoder {
+	if encoding == LevelEncodingNumber {
+		return func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt8(int8(l))
+		}
+	}
+	return func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(levelText(l, encoding))
+	}
+}
+
+func levelText(l zapcore.Level, encoding string) string {
+	name, ok := registeredLevelName(l)
+	switch encoding {
+	case LevelEncodingNumber:
+		return strconv.Itoa(int(l))
+	case LevelEncodingUpper, LevelEncodingCapital:
+		if ok {
+			return strings.ToUpper(name)
+		}
+		return l.CapitalString()
+	default:
+		if ok {
+			return name
+		}
+		return l.String()
+	}
+}
+
+func registeredLevelName(l zapcore.Level) (string, bool) {
+	levelNamesMu.RLock()
+	defer levelNamesMu.RUnlock()
+	name, ok := levelNames[l]
+	return name, ok
+}
+
+func Trace(args ...interface{}) {
+	ensureInitialized()
+
+	globalLogger.Log(TraceLevel, args...)
+}
+
+func Tracef(format string, args ...interface{}) {
+	ensureInitialized()
+
+	globalLogger.Logf(TraceLevel, format, args...)
+}
+
+// Notice logs a significant but normal event. zap has no level between Info
+// and Warn, so notices are filtered and sampled as Info and carry
+// level_alias=notice to tell them apart.
+func Notice(args ...interface{}) {
+	ensureInitialized()
+
+	globalLogger.With(levelAliasKey, "notice").Info(args...)
+}
+
+func Noticef(format string, args ...interface{}) {
+	ensureInitialized()
+
+	globalLogger.With(levelAliasKey, "notice").Infof(format, args...)
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// deferredWrite checks ent against core into a separate entry and adds a
+// per-entry core to ce that writes it once fn has seen the entry's fields.
+// fn returns the fields to write, or false to drop the entry. Cores that
+// decide or rewrite an entry by its fields use it, since zap only passes them
+// to Write.
+func deferredWrite(core zapcore.Core, ent zapcore.Entry, ce *zapcore.CheckedEntry, fn func(zapcore.Entry, []zapcore.Field) ([]zapcore.Field, bool)) *zapcore.CheckedEntry {
+	downstream := core.Check(ent, nil)
+	if downstream == nil {
+		return ce
+	}
+	return ce.AddCore(ent, &deferredCore{Core: core, downstream: downstream, fn: fn})
+}
+
+// deferredCore is the per-entry core added by deferredWrite
+type deferredCore struct {
+	zapcore.Core
+	downstream *zapcore.CheckedEntry
+	fn         func(zapcore.Entry, []zapcore.Field) ([]zapcore.Field, bool)
+}
+
+func (c *deferredCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if fields, ok := c.fn(ent, fields); ok {
+		writeDownstream(c.downstream, ent, fields)
+	}
+	return nil
+}
+
+// writeDownstream writes an entry checked ahead of time, nil when it was
+// dropped
+func writeDownstream(downstream *zapcore.CheckedEntry, ent zapcore.Entry, fields []zapcore.Field) {
+	if downstream == nil {
+		return
+	}
+	// The downstream entry was checked before zap added caller and stack information
+	downstream.Entry = ent
+	downstream.Write(fields...)
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// diskCheckInterval is how often diskGuard checks the free space, statfs is
+// too slow to call per write
+const diskCheckInterval = 10 * time.Second
+
+// diskGuard drops the writes to a log file while the free space of its file
+// system is below Config.MinFreeDiskBytes, so logging cannot fill up the
+// node. Console and other sinks keep logging. Free space is read with
+// syscall.Statfs on Linux and macOS; elsewhere writes are never paused.
+type diskGuard struct {
+	zapcore.WriteSyncer
+	path    string
+	min     int64
+	paused  atomic.Bool
+	dropped atomic.Int64 // writes dropped in the current pause
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newDiskGuard(ws zapcore.WriteSyncer, path string, min int64) *diskGuard {
+	g := &diskGuard{
+		WriteSyncer: ws,
+		path:        path,
+		min:         min,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	g.check()
+	go g.run()
+	return g
+}
+
+func (g *diskGuard) Write(p []byte) (int, error) {
+	if g.paused.Load() {
+		g.dropped.Add(1)
+		return len(p), nil
+	}
+	return g.WriteSyncer.Write(p)
+}
+
+// check updates the pause state, which is kept when the free space is unknown
+func (g *diskGuard) check() {
+	if free, ok := freeDiskBytes(filepath.Dir(g.path)); ok {
+		g.paused.Store(free < g.min)
+	}
+}
+
+// run checks the free space periodically and reports pauses. The initial
+// state is reported on the first tick, logging during build would reenter Init.
+func (g *diskGuard) run() {
+	defer close(g.done)
+	ticker := clock().NewTicker(diskCheckInterval)
+	defer ticker.Stop()
+
+	reported := false
+	for {
+		select {
+		case <-ticker.C:
+			g.check()
+			paused := g.paused.Load()
+			if paused == reported {
+				continue
+			}
+			reported = paused
+			// Called directly rather than through a package function, hence
+			// the skip; written to the other sinks while this file is paused
+			meta := InternalLogger().WithOptions(zap.AddCallerSkip(-1))
+			if paused {
+				meta.Warnw("log file paused, free disk space below minimum",
+					"log_file", g.path, "min_free_disk_bytes", g.min)
+			} else {
+				meta.Infow("log file resumed, free disk space recovered",
+					"log_file", g.path, "dropped", g.dropped.Swap(0))
+			}
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// Close stops the periodic checks
+func (g *diskGuard) Close() {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+		<-g.done
+	})
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// CloudWatch Embedded Metric Format limits
+const (
+	emfMaxMetrics      = 100
+	emfMaxDimensions   = 30
+	emfMaxNameLen      = 255
+	emfMaxDimensionLen = 1024
+)
+
+// EmitMetric logs metrics in CloudWatch Embedded Metric Format at Info level so
+// CloudWatch extracts them from the log stream. All dimensions form a single
+// dimension set. An error is returned, and nothing is logged, when the payload
+// violates the EMF limits.
+func EmitMetric(namespace string, metrics map[string]float64, dimensions map[string]string) error {
+	if err := validateEMF(namespace, metrics, dimensions); err != nil {
+		return err
+	}
+	ensureInitialized()
+
+	dimKeys := sortedKeys(dimensions)
+	metricNames := sortedKeys(metrics)
+
+	definitions := make([]map[string]string, 0, len(metricNames))
+	for _, name := range metricNames {
+		definitions = append(definitions, map[string]string{"Name": name, "Unit": "None"})
+	}
+
+	aws := map[string]interface{}{
+		"Timestamp": time.Now().UnixMilli(),
+		"CloudWatchMetrics": []map[string]interface{}{{
+			"Namespace":  namespace,
+			"Dimensions": [][]string{dimKeys},
+			"Metrics":    definitions,
+		}},
+	}
+
+	kv := make([]interface{}, 0, 2+2*(len(dimKeys)+len(metricNames)))
+	kv = append(kv, "_aws", aws)
+	for _, k := range dimKeys {
+		kv = append(kv, k, dimensions[k])
+	}
+	for _, name := range metricNames {
+		kv = append(kv, name, metrics[name])
+	}
+
+	globalLogger.Infow("metric", kv...)
+	return nil
+}
+
+// validateEMF checks a metric payload against the EMF specification limits
+func validateEMF(namespace string, metrics map[string]float64, dimensions map[string]string) error {
+	if namespace == "" || len(namespace) > emfMaxNameLen {
+		return fmt.Errorf("emf: namespace must be 1-%d characters", emfMaxNameLen)
+	}
+	if len(metrics) == 0 {
+		return fmt.Errorf("emf: at least one metric is required")
+	}
+	if len(metrics) > emfMaxMetrics {
+		return fmt.Errorf("emf: %d metrics exceed the limit of %d", len(metrics), emfMaxMetrics)
+	}
+	if len(dimensions) > emfMaxDimensions {
+		return fmt.Errorf("emf: %d dimensions exceed the limit of %d", len(dimensions), emfMaxDimensions)
+	}
+
+	for name, value := range metrics {
+		if name == "" || len(name) > emfMaxNameLen {
+			return fmt.Errorf("emf: metric name %q must be 1-%d characters", name, emfMaxNameLen)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("emf: metric %q has non-finite value", name)
+		}
+		if _, ok := dimensions[name]; ok {
+			return fmt.Errorf("emf: %q is used as both a metric and a dimension", name)
+		}
+	}
+	for key, value := range dimensions {
+		if key == "" || len(key) > emfMaxNameLen {
+			return fmt.Errorf("emf: dimension name %q must be 1-%d characters", key, emfMaxNameLen)
+		}
+		if value == "" || len(value) > emfMaxDimensionLen {
+			return fmt.Errorf("emf: dimension %q value must be 1-%d characters", key, emfMaxDimensionLen)
+		}
+	}
+	if _, ok := metrics["_aws"]; ok {
+		return fmt.Errorf("emf: _aws is a reserved key")
+	}
+	if _, ok := dimensions["_aws"]; ok {
+		return fmt.Errorf("emf: _aws is a reserved key")
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
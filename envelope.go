@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var envelopePool = buffer.NewPool()
+
+// envelopeEncoder nests the JSON object of every entry under a key, next to
+// static fields: {"source":"tp-logger","payload":{...entry...}}
+type envelopeEncoder struct {
+	zapcore.Encoder
+	prefix []byte // opening brace, static fields and the key, encoded once
+}
+
+// newEnvelope returns a function wrapping JSON encoders in the envelope of
+// cfg, or nil when EnvelopeKey is unset
+func newEnvelope(cfg Config) (func(zapcore.Encoder) zapcore.Encoder, error) {
+	if cfg.EnvelopeKey == "" {
+		return nil, nil
+	}
+
+	var prefix bytes.Buffer
+	prefix.WriteByte('{')
+	for _, k := range sortedKeys(cfg.EnvelopeFields) {
+		if k == cfg.EnvelopeKey {
+			return nil, fmt.Errorf("envelope field %q collides with EnvelopeKey", k)
+		}
+		key, _ := json.Marshal(k)
+		value, err := json.Marshal(cfg.EnvelopeFields[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode envelope field %q: %w", k, err)
+		}
+		prefix.Write(key)
+		prefix.WriteByte(':')
+		prefix.Write(value)
+		prefix.WriteByte(',')
+	}
+	key, _ := json.Marshal(cfg.EnvelopeKey)
+	prefix.Write(key)
+	prefix.WriteByte(':')
+
+	encoded := prefix.Bytes()
+	return func(enc zapcore.Encoder) zapcore.Encoder {
+		return &envelopeEncoder{Encoder: enc, prefix: encoded}
+	}, nil
+}
+
+func (e *envelopeEncoder) Clone() zapcore.Encoder {
+	return &envelopeEncoder{Encoder: e.Encoder.Clone(), prefix: e.prefix}
+}
+
+func (e *envelopeEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	inner, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer inner.Free()
+
+	buf := envelopePool.Get()
+	buf.Write(e.prefix)
+	buf.Write(bytes.TrimRight(inner.Bytes(), "\n"))
+	buf.AppendString("}\n")
+	return buf, nil
+}
@@ -0,0 +1,42 @@
+// Package eventlog provides a zap core writing to the Windows Event Log.
+//
+// The core is only functional on Windows; on other platforms NewCore returns
+// ErrUnsupported, so importing the package does not affect non-Windows builds.
+// Attach the core through Config.ExtraCores:
+//
+//	cfg := logger.Config{ServiceName: "agent"}
+//	core, err := eventlog.NewCore(cfg)
+//	if err != nil {
+//		return err
+//	}
+//	defer core.Close()
+//	cfg.ExtraCores = append(cfg.ExtraCores, core)
+//	logger.MustInit(cfg)
+//
+// Error and above map to Error events, Warn to Warning events and Info to
+// Information events. Debug entries are not sent to the Event Log.
+package eventlog
+
+import (
+	"errors"
+	"os"
+
+	logger "github.com/nglushkov/tp-logger"
+)
+
+// ErrUnsupported is returned by NewCore on platforms without an Event Log
+var ErrUnsupported = errors.New("eventlog: the Windows Event Log is only available on Windows")
+
+// eventID is the event identifier used for all entries
+const eventID = 1
+
+// sourceName returns the event source for cfg, falling back to SERVICE_NAME
+func sourceName(cfg logger.Config) (string, error) {
+	if cfg.ServiceName != "" {
+		return cfg.ServiceName, nil
+	}
+	if name := os.Getenv("SERVICE_NAME"); name != "" {
+		return name, nil
+	}
+	return "", errors.New("eventlog: ServiceName is required as the event source name")
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package eventlog
+
+import (
+	logger "github.com/nglushkov/tp-logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is unavailable outside Windows
+type Core struct {
+	zapcore.Core
+}
+
+// NewCore always returns ErrUnsupported outside Windows
+func NewCore(cfg logger.Config) (*Core, error) {
+	if _, err := sourceName(cfg); err != nil {
+		return nil, err
+	}
+	return nil, ErrUnsupported
+}
+
+// Close is a no-op outside Windows
+func (c *Core) Close() error {
+	return nil
+}
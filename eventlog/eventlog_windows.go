@@ -0,0 +1,96 @@
+//go:build windows
+
+package eventlog
+
+import (
+	"fmt"
+	"strings"
+
+	logger "github.com/nglushkov/tp-logger"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// Core writes entries to the Windows Event Log under the service's event source
+type Core struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	log *eventlog.Log
+}
+
+// NewCore opens the Event Log for cfg.ServiceName. The event source must be
+// registered beforehand, e.g. with eventlog.InstallAsEventCreate during
+// service installation.
+func NewCore(cfg logger.Config) (*Core, error) {
+	source, err := sourceName(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE,
+		`SYSTEM\CurrentControlSet\Services\EventLog\Application\`+source, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, fmt.Errorf("eventlog: event source %q is not registered, register it with eventlog.InstallAsEventCreate: %w", source, err)
+	}
+	key.Close()
+
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("eventlog: failed to open event source %q: %w", source, err)
+	}
+
+	// The Event Log records time and severity itself
+	encCfg := zapcore.EncoderConfig{
+		MessageKey:     "message",
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeTime:     zapcore.RFC3339TimeEncoder,
+	}
+	return &Core{
+		LevelEnabler: zapcore.InfoLevel,
+		enc:          zapcore.NewJSONEncoder(encCfg),
+		log:          log,
+	}, nil
+}
+
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	clone := &Core{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), log: c.log}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+
+	switch {
+	case ent.Level >= zapcore.ErrorLevel:
+		return c.log.Error(eventID, msg)
+	case ent.Level == zapcore.WarnLevel:
+		return c.log.Warning(eventID, msg)
+	default:
+		return c.log.Info(eventID, msg)
+	}
+}
+
+func (c *Core) Sync() error {
+	return nil
+}
+
+// Close releases the event source handle
+func (c *Core) Close() error {
+	return c.log.Close()
+}
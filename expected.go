@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	expectedMu     sync.RWMutex
+	expectedErrors []error
+)
+
+// SetExpectedErrors replaces the sentinel errors ErrorUnexpected treats as
+// expected, e.g. context.Canceled or sql.ErrNoRows. Call without arguments
+// to clear the list.
+func SetExpectedErrors(errs ...error) {
+	expectedMu.Lock()
+	defer expectedMu.Unlock()
+	expectedErrors = append([]error(nil), errs...)
+}
+
+func isExpected(err error) bool {
+	expectedMu.RLock()
+	defer expectedMu.RUnlock()
+	for _, target := range expectedErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCanceled reports whether err is a context cancellation or deadline
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// canceledLevel returns the level cancellations are logged at, see Config.CanceledLevel
+func canceledLevel() zapcore.Level {
+	level, err := zapcore.ParseLevel(globalConfig.CanceledLevel)
+	if err != nil || globalConfig.CanceledLevel == "" {
+		return zapcore.InfoLevel
+	}
+	return level
+}
+
+// ErrorUnexpected logs err at Error. A stacktrace is attached only when err
+// matches none of the errors set with SetExpectedErrors (using errors.Is);
+// a nil err is logged without one. context.Canceled and
+// context.DeadlineExceeded are logged at Config.CanceledLevel without a
+// stacktrace.
+func ErrorUnexpected(msg string, err error, keysAndValues ...interface{}) {
+	ensureInitialized()
+
+	l := globalLogger
+	if isCanceled(err) {
+		l.WithOptions(zap.AddStacktrace(zap.FatalLevel+1)).Logw(canceledLevel(), msg,
+			append([]interface{}{zap.Error(err)}, keysAndValues...)...)
+		return
+	}
+	if err == nil || isExpected(err) {
+		l = l.WithOptions(zap.AddStacktrace(zap.FatalLevel + 1))
+	} else {
+		l = l.WithOptions(zap.AddStacktrace(zap.ErrorLevel))
+	}
+	l.Errorw(msg, append([]interface{}{zap.Error(err)}, keysAndValues...)...)
+}
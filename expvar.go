@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// expvarName is the expvar variable holding the logger metrics
+const expvarName = "logger"
+
+var publishExpvar sync.Once
+
+// PublishExpvar publishes the global logger's metrics under "logger" in expvar,
+// served at /debug/vars by the expvar package:
+//
+//	entries       entries written per level, counted by a hook
+//	dropped       entries shed under memory pressure
+//	write_errors  failed sink writes
+//	last_write    time of the last successful write, RFC 3339
+//
+// Calling it again is a no-op, as is calling it when another package already
+// published a variable named "logger".
+func PublishExpvar() {
+	publishExpvar.Do(func() {
+		if expvar.Get(expvarName) != nil {
+			return
+		}
+		entries := new(expvar.Map)
+		AddHook("expvar", func(ent zapcore.Entry) error {
+			entries.Add(ent.Level.String(), 1)
+			return nil
+		})
+
+		m := expvar.NewMap(expvarName)
+		m.Set("entries", entries)
+		m.Set("dropped", expvar.Func(func() interface{} { return DroppedEntryCount() }))
+		m.Set("write_errors", expvar.Func(func() interface{} { return WriteErrorCount() }))
+		m.Set("last_write", expvar.Func(func() interface{} {
+			if t := LastWriteTime(); !t.IsZero() {
+				return t.Format(time.RFC3339Nano)
+			}
+			return ""
+		}))
+	})
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldEncoders is an immutable set of encoders, replaced on every registration
+type fieldEncoders map[reflect.Type]func(interface{}) interface{}
+
+var (
+	fieldEncodersMu     sync.Mutex
+	activeFieldEncoders atomic.Pointer[fieldEncoders]
+)
+
+// RegisterFieldEncoder transforms values of exactly type t logged by the
+// global logger with fn before they are encoded, e.g. to log a Money as
+// "12.34 USD" wherever it appears in the key-value pairs:
+//
+//	logger.RegisterFieldEncoder(reflect.TypeOf(Money{}), func(v interface{}) interface{} {
+//		return v.(Money).String()
+//	})
+//
+// Registering t again replaces its encoder, a nil fn removes it. Fields
+// attached with With before the registration are not transformed.
+func RegisterFieldEncoder(t reflect.Type, fn func(interface{}) interface{}) {
+	fieldEncodersMu.Lock()
+	defer fieldEncodersMu.Unlock()
+
+	next := fieldEncoders{}
+	if cur := activeFieldEncoders.Load(); cur != nil {
+		for k, v := range *cur {
+			next[k] = v
+		}
+	}
+	if fn == nil {
+		delete(next, t)
+	} else {
+		next[t] = fn
+	}
+	if len(next) == 0 {
+		activeFieldEncoders.Store(nil)
+		return
+	}
+	activeFieldEncoders.Store(&next)
+}
+
+// encode returns fields with the registered types transformed, fields itself
+// when none matched
+func (e fieldEncoders) encode(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		if f.Interface == nil {
+			continue
+		}
+		fn, ok := e[reflect.TypeOf(f.Interface)]
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append(make([]zapcore.Field, 0, len(fields)), fields...)
+		}
+		out[i] = zap.Any(f.Key, fn(f.Interface))
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// fieldEncoderCore applies the registered field encoders ahead of the rest of
+// the pipeline, so filters and hooks see the transformed values
+type fieldEncoderCore struct {
+	zapcore.Core
+}
+
+func (c *fieldEncoderCore) With(fields []zapcore.Field) zapcore.Core {
+	if encoders := activeFieldEncoders.Load(); encoders != nil {
+		fields = encoders.encode(fields)
+	}
+	return &fieldEncoderCore{Core: c.Core.With(fields)}
+}
+
+func (c *fieldEncoderCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	encoders := activeFieldEncoders.Load()
+	if encoders == nil {
+		return c.Core.Check(ent, ce)
+	}
+
+	return deferredWrite(c.Core, ent, ce, func(_ zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+		return encoders.encode(fields), true
+	})
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxFieldLevels caps the active SetLevelForField overrides; every override
+// adds a comparison to each entry that could match
+const maxFieldLevels = 64
+
+// fieldLevels is an immutable set of overrides, replaced on every change
+type fieldLevels struct {
+	levels map[string]map[string]zapcore.Level // field -> value -> level
+	min    zapcore.Level
+	count  int
+}
+
+var (
+	fieldLevelsMu sync.Mutex
+	activeFields  atomic.Pointer[fieldLevels]
+)
+
+// SetLevelForField evaluates entries carrying field=value, as a context field
+// or an entry field, at level instead of the global or named level, e.g. to
+// get Debug logs for a single user in production:
+//
+//	logger.SetLevelForField("user_id", "u-123", zapcore.DebugLevel)
+//
+// Only string fields match. At most 64 overrides can be active at a time.
+func SetLevelForField(field, value string, level zapcore.Level) error {
+	fieldLevelsMu.Lock()
+	defer fieldLevelsMu.Unlock()
+
+	next := copyFieldLevels(activeFields.Load())
+	if _, ok := next.levels[field][value]; !ok {
+		if next.count >= maxFieldLevels {
+			return fmt.Errorf("cannot override level for %s=%s: %d field level overrides are active already", field, value, maxFieldLevels)
+		}
+		next.count++
+	}
+	if next.levels[field] == nil {
+		next.levels[field] = map[string]zapcore.Level{}
+	}
+	next.levels[field][value] = level
+	storeFieldLevels(next)
+	return nil
+}
+
+// ClearLevelForField removes the override for field=value
+func ClearLevelForField(field, value string) {
+	fieldLevelsMu.Lock()
+	defer fieldLevelsMu.Unlock()
+
+	next := copyFieldLevels(activeFields.Load())
+	if _, ok := next.levels[field][value]; !ok {
+		return
+	}
+	delete(next.levels[field], value)
+	if len(next.levels[field]) == 0 {
+		delete(next.levels, field)
+	}
+	next.count--
+	storeFieldLevels(next)
+}
+
+// ClearFieldLevels removes all SetLevelForField overrides
+func ClearFieldLevels() {
+	fieldLevelsMu.Lock()
+	defer fieldLevelsMu.Unlock()
+	activeFields.Store(nil)
+}
+
+func copyFieldLevels(cur *fieldLevels) *fieldLevels {
+	next := &fieldLevels{levels: map[string]map[string]zapcore.Level{}}
+	if cur == nil {
+		return next
+	}
+	for field, values := range cur.levels {
+		next.levels[field] = make(map[string]zapcore.Level, len(values))
+		for value, level := range values {
+			next.levels[field][value] = level
+		}
+	}
+	next.count = cur.count
+	return next
+}
+
+// storeFieldLevels publishes next with its minimum level; the caller holds fieldLevelsMu
+func storeFieldLevels(next *fieldLevels) {
+	if next.count == 0 {
+		activeFields.Store(nil)
+		return
+	}
+	first := true
+	for _, values := range next.levels {
+		for _, level := range values {
+			if first || level < next.min {
+				next.min, first = level, false
+			}
+		}
+	}
+	activeFields.Store(next)
+}
+
+// match returns the level of the first override matching one of the field sets
+func (f *fieldLevels) match(sets ...[]zapcore.Field) (zapcore.Level, bool) {
+	for _, fields := range sets {
+		for _, field := range fields {
+			if field.Type != zapcore.StringType {
+				continue
+			}
+			if level, ok := f.levels[field.Key][field.String]; ok {
+				return level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// fieldLevelCore is the level gate of a pipeline. Without overrides it is a
+// plain levelCore; with overrides, entries that an override could enable are
+// checked past the gate and decided at write time, when their fields are known.
+type fieldLevelCore struct {
+	zapcore.Core // ungated
+	gate         *levelCore
+	fields       []zapcore.Field // context fields, matched along with the entry's
+}
+
+func newGatedCore(core zapcore.Core, level zapcore.LevelEnabler) zapcore.Core {
+	return &fieldLevelCore{Core: core, gate: &levelCore{Core: core, level: level}}
+}
+
+func (c *fieldLevelCore) Enabled(l zapcore.Level) bool {
+	if c.gate.Enabled(l) {
+		return true
+	}
+	overrides := activeFields.Load()
+	return overrides != nil && l >= overrides.min && !disabledAt(l)
+}
+
+func (c *fieldLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	core := c.Core.With(fields)
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return &fieldLevelCore{
+		Core:   core,
+		gate:   c.gate.with(core, fields),
+		fields: append(merged, fields...),
+	}
+}
+
+func (c *fieldLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	overrides := activeFields.Load()
+	if overrides == nil || disabledAt(ent.Level) {
+		return c.gate.Check(ent, ce)
+	}
+
+	// The context fields alone decide overrides for most loggers, e.g. a
+	// request logger carrying user_id
+	if level, ok := overrides.match(c.fields); ok {
+		if level.Enabled(ent.Level) {
+			return c.Core.Check(ent, ce)
+		}
+		return ce
+	}
+
+	normal := c.gate.levelFor(ent.LoggerName).Enabled(ent.Level)
+	if !normal && ent.Level < overrides.min {
+		return ce
+	}
+	// normal is the decision without an override on the entry's fields
+	return deferredWrite(c.Core, ent, ce, func(ent zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+		if level, ok := overrides.match(fields); ok {
+			return fields, level.Enabled(ent.Level)
+		}
+		return fields, normal
+	})
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// toFields converts loosely-typed key-value pairs into zap fields following the
+// sugared logger's rules: zap fields and errors are taken as-is, a dangling key
+// is kept under "ignored" and non-string keys are formatted.
+func toFields(keysAndValues []interface{}) []zap.Field {
+	fields := make([]zap.Field, 0, len(keysAndValues))
+	for i := 0; i < len(keysAndValues); {
+		switch v := keysAndValues[i].(type) {
+		case zap.Field:
+			fields = append(fields, v)
+			i++
+			continue
+		case error:
+			fields = append(fields, zap.Error(v))
+			i++
+			continue
+		}
+
+		if i == len(keysAndValues)-1 {
+			fields = append(fields, zap.Any("ignored", keysAndValues[i]))
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		i += 2
+	}
+	return fields
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxFlattenDepth is how many levels FlattenFields turns into dotted keys;
+// anything nested deeper is logged as an object under the dotted key
+const maxFlattenDepth = 8
+
+// flattenCore rewrites object, map and struct fields into dotted keys, e.g.
+// user={"id":1} becomes user.id=1, and prefixes the fields of a namespace
+// with its name. Arrays are leaves: they are logged as arrays and their
+// elements are not flattened. Structs follow their JSON form.
+type flattenCore struct {
+	zapcore.Core
+	prefix string // namespaces opened by With
+}
+
+func (c *flattenCore) With(fields []zapcore.Field) zapcore.Core {
+	flat, prefix := flattenFields(fields, c.prefix)
+	return &flattenCore{Core: c.Core.With(flat), prefix: prefix}
+}
+
+func (c *flattenCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *flattenCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	flat, _ := flattenFields(fields, c.prefix)
+	return c.Core.Write(ent, flat)
+}
+
+// flattenFields returns fields with nested values flattened and the key
+// prefix left open by namespaces
+func flattenFields(fields []zapcore.Field, prefix string) ([]zapcore.Field, string) {
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		switch f.Type {
+		case zapcore.NamespaceType:
+			prefix += f.Key + "."
+			continue
+		case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			if f.Type == zapcore.InlineMarshalerType {
+				out = appendFlattened(out, prefix, enc.Fields, 1)
+			} else {
+				out = appendFlattened(out, prefix, map[string]interface{}{f.Key: enc.Fields[f.Key]}, 0)
+			}
+			continue
+		case zapcore.ReflectType:
+			if v, ok := jsonValue(f.Interface); ok {
+				out = appendFlattened(out, prefix, map[string]interface{}{f.Key: v}, 0)
+				continue
+			}
+		}
+		if prefix != "" {
+			f.Key = prefix + f.Key
+		}
+		out = append(out, f)
+	}
+	return out, prefix
+}
+
+func appendFlattened(out []zapcore.Field, prefix string, values map[string]interface{}, depth int) []zapcore.Field {
+	for _, k := range sortedKeys(values) {
+		if nested, ok := values[k].(map[string]interface{}); ok && depth < maxFlattenDepth && len(nested) > 0 {
+			out = appendFlattened(out, prefix+k+".", nested, depth+1)
+			continue
+		}
+		out = append(out, flatLeaf(prefix+k, values[k]))
+	}
+	return out
+}
+
+func flatLeaf(key string, v interface{}) zapcore.Field {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return zap.Int64(key, i)
+		}
+		if f, err := n.Float64(); err == nil {
+			return zap.Float64(key, f)
+		}
+	}
+	return zap.Any(key, v)
+}
+
+// jsonValue returns v decoded from its JSON form, so maps and structs become
+// map[string]interface{} and numbers keep their exact text
+func jsonValue(v interface{}) (interface{}, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
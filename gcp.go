@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// cloudTraceKey is the field Cloud Logging uses to link an entry to a trace
+const cloudTraceKey = "logging.googleapis.com/trace"
+
+// gcpSeverities maps zap levels to Cloud Logging severities
+var gcpSeverities = map[zapcore.Level]string{
+	TraceLevel:          "DEBUG",
+	zapcore.DebugLevel:  "DEBUG",
+	zapcore.InfoLevel:   "INFO",
+	zapcore.WarnLevel:   "WARNING",
+	zapcore.ErrorLevel:  "ERROR",
+	zapcore.DPanicLevel: "CRITICAL",
+	zapcore.PanicLevel:  "ALERT",
+	zapcore.FatalLevel:  "EMERGENCY",
+}
+
+// gcpLevelEncoder encodes levels as Cloud Logging severities
+func gcpLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	severity, ok := gcpSeverities[l]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	enc.AppendString(severity)
+}
+
+// applyCloudLogging switches the encoder to the field names Cloud Logging and Cloud Run expect
+func applyCloudLogging(enc *zapcore.EncoderConfig) {
+	enc.LevelKey = "severity"
+	enc.EncodeLevel = gcpLevelEncoder
+	enc.TimeKey = "time"
+	enc.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	enc.MessageKey = "message"
+}
+
+// traceField returns the key and value under which a trace ID is logged. In
+// Cloud Logging format the ID is qualified with GOOGLE_CLOUD_PROJECT when set.
+func traceField(cfg Config, id string) (string, string) {
+	if !cfg.CloudLoggingFormat {
+		return "trace_id", id
+	}
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		return cloudTraceKey, fmt.Sprintf("projects/%s/traces/%s", project, id)
+	}
+	return cloudTraceKey, id
+}
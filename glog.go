@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// glogSeverities lists the per-severity files created in GlogStyle mode. Each
+// file receives entries at its level and above; INFO also receives Debug and
+// Trace entries when they are enabled.
+var glogSeverities = []struct {
+	name  string
+	level zapcore.Level
+}{
+	{"INFO", TraceLevel},
+	{"WARNING", zap.WarnLevel},
+	{"ERROR", zap.ErrorLevel},
+	{"FATAL", zap.FatalLevel},
+}
+
+// openGlogCores creates glog-style files named
+// {service}.{host}.log.{SEVERITY}.{yyyymmdd-hhmmss}.{pid} next to LogFile,
+// points the {service}.{SEVERITY} symlinks at them and points LogFile itself
+// at the INFO file. Symlinks left over from a previous run are replaced.
+func openGlogCores(
+	cfg Config,
+	newSinkCore func(zapcore.WriteSyncer, zapcore.LevelEnabler) zapcore.Core,
+) ([]zapcore.Core, func(), error) {
+	dir := filepath.Dir(cfg.LogFile)
+	stamp := time.Now().Format("20060102-150405")
+
+	var (
+		cores   []zapcore.Core
+		closers []func()
+	)
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	for _, sev := range glogSeverities {
+		name := fmt.Sprintf("%s.%s.log.%s.%s.%d", cfg.ServiceName, cfg.Hostname, sev.name, stamp, os.Getpid())
+		path := filepath.Join(dir, name)
+
+		sink, closeFile, err := openFileSink(cfg, path)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, closeFile)
+
+		links := []string{filepath.Join(dir, cfg.ServiceName+"."+sev.name)}
+		if sev.name == "INFO" {
+			links = append(links, cfg.LogFile)
+		}
+		for _, link := range links {
+			if err := replaceSymlink(name, link); err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+		}
+
+		floor := sev.level
+		enab := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= floor
+		})
+		cores = append(cores, newSinkCore(sink, enab))
+	}
+
+	return cores, closeAll, nil
+}
+
+// replaceSymlink points link at target, a file name relative to the link's
+// directory, removing a stale symlink first. A regular file at link is left untouched.
+func replaceSymlink(target, link string) error {
+	if info, err := os.Lstat(link); err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("cannot create glog symlink %q: a regular file exists at that path", link)
+		}
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("failed to remove stale symlink %q: %w", link, err)
+		}
+	}
+
+	if err := os.Symlink(target, link); err != nil {
+		return fmt.Errorf("failed to create symlink %q: %w", link, err)
+	}
+	return nil
+}
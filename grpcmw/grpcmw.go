@@ -0,0 +1,121 @@
+// Package grpcmw propagates the trace ID of the logger package's context
+// baggage through gRPC metadata.
+//
+// Client interceptors copy the baggage trace ID of the call context into the
+// outgoing metadata; server interceptors read it from the incoming metadata
+// into the baggage of the handler context, so the logger package's Ctx
+// functions on both sides log the same trace_id:
+//
+//	conn, err := grpc.NewClient(target,
+//		grpc.WithUnaryInterceptor(grpcmw.UnaryClientInterceptor()),
+//		grpc.WithStreamInterceptor(grpcmw.StreamClientInterceptor()))
+//
+//	srv := grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcmw.UnaryServerInterceptor()),
+//		grpc.StreamInterceptor(grpcmw.StreamServerInterceptor()))
+//
+// The metadata key is DefaultMetadataKey unless changed with WithMetadataKey,
+// which clients and servers must agree on.
+package grpcmw
+
+import (
+	"context"
+	"strings"
+
+	logger "github.com/nglushkov/tp-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultMetadataKey is the metadata key carrying the trace ID
+const DefaultMetadataKey = "x-trace-id"
+
+type options struct {
+	key string
+}
+
+// Option configures the interceptors
+type Option func(*options)
+
+// WithMetadataKey sets the metadata key carrying the trace ID. Keys are
+// lower-cased as gRPC requires.
+func WithMetadataKey(key string) Option {
+	return func(o *options) {
+		o.key = strings.ToLower(key)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{key: DefaultMetadataKey}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// UnaryClientInterceptor sends the baggage trace ID of the call context in
+// the outgoing metadata. Calls without a trace ID, or whose metadata already
+// has the key, are passed on unchanged.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		return invoker(o.outgoing(ctx), method, req, reply, cc, callOpts...)
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming calls
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(o.outgoing(ctx), desc, cc, method, callOpts...)
+	}
+}
+
+// UnaryServerInterceptor puts the trace ID of the incoming metadata into the
+// baggage of the handler context. Without one the context is left as is.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(o.incoming(ctx), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: o.incoming(ss.Context())})
+	}
+}
+
+func (o options) outgoing(ctx context.Context) context.Context {
+	traceID := logger.BaggageFromContext(ctx).TraceID
+	if traceID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(o.key)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, o.key, traceID)
+}
+
+func (o options) incoming(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if values := md.Get(o.key); len(values) > 0 && values[0] != "" {
+		return logger.ContextWithBaggage(ctx, logger.Baggage{TraceID: values[0]})
+	}
+	return ctx
+}
+
+// serverStream replaces the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var headerPool = buffer.NewPool()
+
+// headerEncoder writes the standard header fields in a fixed leading order,
+// timestamp, level, message and caller, followed by the body of the wrapped
+// JSON encoder: logger name, context fields, entry fields and stacktrace.
+// zap's own order puts the level first and the message after the caller.
+type headerEncoder struct {
+	zapcore.Encoder                   // body, built without the header keys
+	header          []zapcore.Encoder // one encoder per header key, in order
+}
+
+// newHeaderEncoder builds the body encoder with newBody from cfg minus the
+// header keys
+func newHeaderEncoder(cfg zapcore.EncoderConfig, newBody func(zapcore.EncoderConfig) zapcore.Encoder) *headerEncoder {
+	only := cfg
+	only.TimeKey, only.LevelKey, only.MessageKey, only.CallerKey = "", "", "", ""
+	only.NameKey, only.FunctionKey, only.StacktraceKey = "", "", ""
+	only.SkipLineEnding = true
+
+	var header []zapcore.Encoder
+	for _, set := range []func(*zapcore.EncoderConfig){
+		func(c *zapcore.EncoderConfig) { c.TimeKey = cfg.TimeKey },
+		func(c *zapcore.EncoderConfig) { c.LevelKey = cfg.LevelKey },
+		func(c *zapcore.EncoderConfig) { c.MessageKey = cfg.MessageKey },
+		func(c *zapcore.EncoderConfig) { c.CallerKey = cfg.CallerKey },
+	} {
+		c := only
+		set(&c)
+		header = append(header, zapcore.NewJSONEncoder(c))
+	}
+
+	body := cfg
+	body.TimeKey, body.LevelKey, body.MessageKey, body.CallerKey = "", "", "", ""
+	return &headerEncoder{Encoder: newBody(body), header: header}
+}
+
+func (e *headerEncoder) Clone() zapcore.Encoder {
+	return &headerEncoder{Encoder: e.Encoder.Clone(), header: e.header}
+}
+
+func (e *headerEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf := headerPool.Get()
+	buf.AppendByte('{')
+	wrote := false
+	for _, h := range e.header {
+		part, err := h.EncodeEntry(ent, nil)
+		if err != nil {
+			buf.Free()
+			return nil, err
+		}
+		// {"key":value}, or {} for an omitted key or caller
+		if inner := part.Bytes()[1 : part.Len()-1]; len(inner) > 0 {
+			if wrote {
+				buf.AppendByte(',')
+			}
+			buf.Write(inner)
+			wrote = true
+		}
+		part.Free()
+	}
+
+	body, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		buf.Free()
+		return nil, err
+	}
+	defer body.Free()
+	rest := body.Bytes()[1:] // without the opening brace
+	if wrote && len(rest) > 0 && rest[0] != '}' {
+		buf.AppendByte(',')
+	}
+	buf.Write(rest)
+	return buf, nil
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestHeaderOrderGolden(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		sorted bool
+	}{
+		{"header_order", false},
+		{"header_order_sorted", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Snapshot()()
+			err := Init(Config{
+				ServiceName:      "checkout",
+				Environment:      "test",
+				Version:          "1.2.3",
+				Hostname:         "web-1",
+				TraceID:          "0af7651916cd43dd8448eb211c80319c",
+				LogFile:          filepath.Join(t.TempDir(), "app.log"),
+				AdditionalFields: map[string]interface{}{"region": "eu-west-1"},
+				SortFields:       tt.sorted,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer Close()
+
+			var out bytes.Buffer
+			core := zapcore.NewCore(globalPipeline.newEncoder(), zapcore.AddSync(&out), zapcore.DebugLevel).
+				With(globalPipeline.initialFields).
+				With([]zapcore.Field{zap.String("zone", "b"), zap.String("request_id", "r-1")})
+			ent := zapcore.Entry{
+				Level:      zapcore.WarnLevel,
+				Time:       time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+				LoggerName: "payments",
+				Message:    "charge declined",
+				Caller:     zapcore.NewEntryCaller(0, "/src/payments/charge.go", 42, true),
+			}
+			if err := core.Write(ent, []zapcore.Field{zap.Int("amount", 1200), zap.String("currency", "EUR")}); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != string(want) {
+				t.Fatalf("entry =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// write error count seen by the previous HealthCheck, reset by Init
+var healthErrors atomic.Uint64
+
+// HealthCheck reports whether the log pipeline is working. It fails when the
+// logger fell back to console-only output, when writes failed since the
+// previous check (e.g. disk full), while writes go to the fallback file because
+// LogFile is stalled, or when the log file can no longer be opened for
+// writing; the file is not probed with DisableFile. A logger installed with
+// Use is always reported healthy since its sinks belong to the caller. It
+// writes nothing and is cheap enough for frequent probes.
+func HealthCheck() error {
+	ensureInitialized()
+	if usedLogger {
+		return nil
+	}
+	if globalStats == nil {
+		return fmt.Errorf("logger is running on the console-only fallback")
+	}
+
+	errs := globalStats.errors.Load()
+	if prev := healthErrors.Swap(errs); errs > prev {
+		return fmt.Errorf("%d log writes failed since the last health check", errs-prev)
+	}
+
+	if globalStats.degraded.Load() {
+		return fmt.Errorf("log file is stalled, writing to %s", globalConfig.FallbackLogFile)
+	}
+
+	if globalConfig.DisableFile {
+		return nil
+	}
+	f, err := os.OpenFile(globalConfig.LogFile, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("log file is not writable: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthCheckAfterReInit(t *testing.T) {
+	defer Snapshot()()
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	if err := Init(Config{ServiceName: "test", LogFile: path}); err != nil {
+		t.Fatal(err)
+	}
+	globalStats.errors.Add(5)
+	if err := HealthCheck(); err == nil {
+		t.Fatal("HealthCheck passed after 5 failed writes")
+	}
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new pipeline fails fewer writes than the old one did in total
+	if err := Init(Config{ServiceName: "test", LogFile: path}); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+	globalStats.errors.Add(1)
+	if err := HealthCheck(); err == nil {
+		t.Fatal("HealthCheck passed after a failed write of the new pipeline")
+	}
+}
@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HookID identifies a registered hook, filter or writer
+type HookID uint64
+
+// HookInfo describes a registered hook, filter or writer
+type HookInfo struct {
+	ID   HookID
+	Name string
+}
+
+type hookEntry struct {
+	HookInfo
+	fn func(zapcore.Entry) error
+}
+
+type filterEntry struct {
+	HookInfo
+	fn func(zapcore.Entry, []zapcore.Field) bool
+}
+
+type writerEntry struct {
+	HookInfo
+	ws zapcore.WriteSyncer
+}
+
+// Registrations are copy-on-write slices guarded by registryMu, so the logging
+// path only holds the read lock long enough to grab the current slice.
+var (
+	registryMu sync.RWMutex
+	nextHookID HookID
+	hooks      []hookEntry
+	filters    []filterEntry
+	writers    []writerEntry
+
+	// goroutines currently running hooks, used as a re-entrancy guard
+	hooksActive sync.Map
+
+	internal *zap.SugaredLogger
+)
+
+func newHookInfo(name string) HookInfo {
+	nextHookID++
+	return HookInfo{ID: nextHookID, Name: name}
+}
+
+// AddHook registers a function called after every entry the global logger writes.
+//
+// Hooks may log through this package: entries logged from inside a hook are
+// written normally but do not run the hooks again, which prevents infinite
+// recursion. The guard is per goroutine, so a hook that hands logging off to
+// another goroutine and waits for it is not protected. Hook authors can use
+// InternalLogger to bypass the hook pipeline entirely.
+func AddHook(name string, hook func(zapcore.Entry) error) HookID {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	e := hookEntry{HookInfo: newHookInfo(name), fn: hook}
+	hooks = append(hooks[:len(hooks):len(hooks)], e)
+	return e.ID
+}
+
+// AddFilter registers a filter for the global logger. Entries for which any
+// filter returns false are dropped before they reach hooks and sinks.
+func AddFilter(name string, filter func(zapcore.Entry, []zapcore.Field) bool) HookID {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	e := filterEntry{HookInfo: newHookInfo(name), fn: filter}
+	filters = append(filters[:len(filters):len(filters)], e)
+	return e.ID
+}
+
+// AddWriter registers an additional destination receiving every entry of the
+// global logger, encoded like the log file. Writes to w are serialized.
+func AddWriter(name string, w io.Writer) HookID {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	e := writerEntry{HookInfo: newHookInfo(name), ws: zapcore.Lock(zapcore.AddSync(w))}
+	writers = append(writers[:len(writers):len(writers)], e)
+	return e.ID
+}
+
+// ListHooks returns the registered hooks in registration order
+func ListHooks() []HookInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return infos(hooks)
+}
+
+// ListFilters returns the registered filters in registration order
+func ListFilters() []HookInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return infos(filters)
+}
+
+// ListWriters returns the registered writers in registration order
+func ListWriters() []HookInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return infos(writers)
+}
+
+// RemoveHook unregisters a hook and reports whether it was registered
+func RemoveHook(id HookID) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	var ok bool
+	hooks, ok = without(hooks, id)
+	return ok
+}
+
+// RemoveFilter unregisters a filter and reports whether it was registered
+func RemoveFilter(id HookID) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	var ok bool
+	filters, ok = without(filters, id)
+	return ok
+}
+
+// RemoveWriter unregisters a writer and reports whether it was registered.
+// Entries being written concurrently may still reach it.
+func RemoveWriter(id HookID) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	var ok bool
+	writers, ok = without(writers, id)
+	return ok
+}
+
+func (i HookInfo) info() HookInfo { return i }
+
+type registered interface {
+	info() HookInfo
+}
+
+func infos[T registered](entries []T) []HookInfo {
+	out := make([]HookInfo, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.info())
+	}
+	return out
+}
+
+// without returns a copy of entries without id, leaving the original slice
+// intact for readers still holding it
+func without[T registered](entries []T, id HookID) ([]T, bool) {
+	for i, e := range entries {
+		if e.info().ID == id {
+			out := make([]T, 0, len(entries)-1)
+			out = append(out, entries[:i]...)
+			return append(out, entries[i+1:]...), true
+		}
+	}
+	return entries, false
+}
+
+// InternalLogger returns the global logger without filters, hooks and writers
+func InternalLogger() *zap.SugaredLogger {
+	ensureInitialized()
+	if internal == nil {
+		return globalLogger
+	}
+	return internal
+}
+
+// hookCore runs the registered hooks for every entry accepted downstream
+type hookCore struct {
+	zapcore.Core
+}
+
+func (c *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hookCore{Core: c.Core.With(fields)}
+}
+
+func (c *hookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if downstream := c.Core.Check(ent, ce); downstream != nil {
+		return downstream.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *hookCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	registryMu.RLock()
+	registered := hooks
+	registryMu.RUnlock()
+	if len(registered) == 0 {
+		return nil
+	}
+
+	gid := goroutineID()
+	if _, busy := hooksActive.LoadOrStore(gid, struct{}{}); busy {
+		// Logged from inside a hook
+		return nil
+	}
+	defer hooksActive.Delete(gid)
+
+	var err error
+	for _, hook := range registered {
+		err = multierr.Append(err, hook.fn(ent))
+	}
+	return err
+}
+
+// goroutineID parses the current goroutine's ID from its stack header
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// filterCore drops entries rejected by a registered filter. Filters need the
+// entry's fields, so the decision is made at write time: the downstream cores
+// are checked into a separate entry that is only written when all filters pass.
+type filterCore struct {
+	zapcore.Core
+	fields []zapcore.Field // context fields, passed to filters along with the entry's
+}
+
+func (c *filterCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return &filterCore{Core: c.Core.With(fields), fields: append(merged, fields...)}
+}
+
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	registryMu.RLock()
+	active := len(filters) > 0
+	registryMu.RUnlock()
+	if !active {
+		return c.Core.Check(ent, ce)
+	}
+
+	return deferredWrite(c.Core, ent, ce, c.filter)
+}
+
+// filter passes an entry with its context fields to the registered filters
+func (c *filterCore) filter(ent zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+	registryMu.RLock()
+	registered := filters
+	registryMu.RUnlock()
+
+	all := fields
+	if len(c.fields) > 0 {
+		all = append(append(make([]zapcore.Field, 0, len(c.fields)+len(fields)), c.fields...), fields...)
+	}
+	for _, f := range registered {
+		if !f.fn(ent, all) {
+			return nil, false
+		}
+	}
+	return fields, true
+}
+
+// writerCore encodes entries for the registered writers
+type writerCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+}
+
+func (c *writerCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &writerCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone()}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *writerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *writerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	registryMu.RLock()
+	registered := writers
+	registryMu.RUnlock()
+	if len(registered) == 0 {
+		return nil
+	}
+
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	for _, w := range registered {
+		_, werr := w.ws.Write(buf.Bytes())
+		err = multierr.Append(err, werr)
+	}
+	return err
+}
+
+func (c *writerCore) Sync() error {
+	registryMu.RLock()
+	registered := writers
+	registryMu.RUnlock()
+
+	var err error
+	for _, w := range registered {
+		err = multierr.Append(err, w.ws.Sync())
+	}
+	return err
+}
+
+// registryCore wraps core with the package-level registry: level fields,
+// field encoders and package tags first so filters see them, then filters
+// and hooks
+func registryCore(core zapcore.Core) zapcore.Core {
+	return &levelFieldCore{Core: &fieldEncoderCore{Core: &tagCore{Core: &filterCore{Core: &hookCore{Core: core}}}}}
+}
+
+// globalCore wraps a pipeline core with the registry, with the registered
+// writers teed next to the sinks. With a PanicFormatter, panicCore goes on
+// top to see all context fields.
+func (p *pipeline) globalCore(core zapcore.Core) zapcore.Core {
+	// core already carries the initial fields, the writer core needs them added
+	writer := (&writerCore{LevelEnabler: allLevels, enc: p.newEncoder()}).With(p.initialFields)
+	withWriters := zapcore.NewTee(core, newLevelCore(writer, p.level))
+	registry := registryCore(withWriters)
+	if p.config.PanicFormatter == nil {
+		return registry
+	}
+	return &panicCore{Core: registry, format: p.config.PanicFormatter, fields: p.initialFields}
+}
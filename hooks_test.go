@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHookLoggingDoesNotRecurse(t *testing.T) {
+	defer Snapshot()()
+	core, logs := observer.New(zapcore.DebugLevel)
+	Use(zap.New(core))
+
+	var calls atomic.Int32
+	AddHook("alert", func(ent zapcore.Entry) error {
+		calls.Add(1)
+		Error("hook saw: " + ent.Message)
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Error("payment failed")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Error from inside a hook deadlocked")
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("hook ran %d times, want 1", n)
+	}
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	if len(got) != 2 || got[0] != "payment failed" || got[1] != "hook saw: payment failed" {
+		t.Fatalf("logged %q, want the entry and the one of the hook", got)
+	}
+}
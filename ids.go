@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Supported values for Config.IDFormat
+const (
+	IDFormatUUID   = "uuid"   // random UUID v4
+	IDFormatBase62 = "base62" // 16 random base62 characters
+	IDFormatKSUID  = "ksuid"  // 27 characters, sortable by second
+	IDFormatULID   = "ulid"   // 26 characters, sortable by millisecond
+)
+
+const (
+	base62Alphabet  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	ksuidEpoch      = 1400000000 // KSUID timestamps count seconds from 2014-05-13
+)
+
+// NewID returns a new unique ID in the configured Config.IDFormat, base62 by
+// default, e.g. for request IDs
+func NewID() string {
+	format := globalConfig.IDFormat
+	if format == "" {
+		format = IDFormatBase62
+	}
+	return newID(format)
+}
+
+func newID(format string) string {
+	switch format {
+	case IDFormatUUID:
+		return newUUID()
+	case IDFormatKSUID:
+		return newKSUID(time.Now())
+	case IDFormatULID:
+		return newULID(time.Now())
+	default:
+		return newBase62(16)
+	}
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never fails on supported platforms
+	_, _ = rand.Read(b)
+	return b
+}
+
+func newUUID() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func newBase62(n int) string {
+	out := make([]byte, n)
+	for i, b := range randomBytes(n) {
+		// 248 is the largest multiple of 62 below 256; rejecting above it avoids bias
+		for b >= 248 {
+			b = randomBytes(1)[0]
+		}
+		out[i] = base62Alphabet[b%62]
+	}
+	return string(out)
+}
+
+// newKSUID encodes a 32-bit timestamp and 128 random bits as 27 base62 characters
+func newKSUID(t time.Time) string {
+	raw := make([]byte, 4, 20)
+	binary.BigEndian.PutUint32(raw, uint32(t.Unix()-ksuidEpoch))
+	raw = append(raw, randomBytes(16)...)
+
+	n := new(big.Int).SetBytes(raw)
+	base := big.NewInt(62)
+	mod := new(big.Int)
+	out := make([]byte, 27)
+	for i := len(out) - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = base62Alphabet[mod.Int64()]
+	}
+	return string(out)
+}
+
+// newULID encodes a 48-bit millisecond timestamp and 80 random bits as 26
+// Crockford base32 characters
+func newULID(t time.Time) string {
+	raw := make([]byte, 16)
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		raw[i] = byte(ms)
+		ms >>= 8
+	}
+	copy(raw[6:], randomBytes(10))
+
+	// 128 bits as 26 characters of 5 bits, the first one holding the top 3 bits
+	n := new(big.Int).SetBytes(raw)
+	mask := big.NewInt(31)
+	out := make([]byte, 26)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordBase32[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 5)
+	}
+	return string(out)
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// JobLogger starts a run of a background job, which has no request to
+// correlate with. The run gets a fresh trace_id and a job_run_id that, with
+// job=jobName, are attached to the returned logger and carried by the
+// returned context for the Ctx functions. The start is
+// logged at Info; call the returned function when the run finishes to log
+// its completion with the duration:
+//
+//	log, ctx, done := logger.JobLogger("nightly-cleanup")
+//	defer done()
+func JobLogger(jobName string) (*zap.SugaredLogger, context.Context, func()) {
+	ensureInitialized()
+
+	ctx := ContextWithBaggage(context.Background(), Baggage{
+		TraceID: generateTraceID(globalConfig.IDFormat),
+		Extra:   map[string]string{"job": jobName, "job_run_id": NewID()},
+	})
+	start := clock().Now()
+	l := ctxLogger(ctx)
+	l.Infow("job started")
+	// Returned for direct use, so drop the skip meant for the package functions
+	return l.WithOptions(zap.AddCallerSkip(-1)), ctx, func() {
+		l.Infow("job finished", "duration", clock().Now().Sub(start))
+	}
+}
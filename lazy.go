@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// lazyValue computes a field value when the entry is encoded
+type lazyValue struct {
+	once  sync.Once
+	fn    func() interface{}
+	value []byte
+	err   error
+}
+
+// Lazy wraps an expensive field value so fn only runs if an entry carrying it
+// is actually encoded:
+//
+//	logger.InfoStruct("state", "dump", logger.Lazy(expensiveDump))
+//
+// fn is not called when the level is disabled or the entry is sampled or
+// filtered out, and at most once however many sinks encode the entry. The
+// result is logged like a value passed to zap.Any through its JSON form.
+// Context fields are encoded when the logger is created, so in WithFields fn
+// runs once right away rather than per entry.
+func Lazy(fn func() interface{}) interface{} {
+	return &lazyValue{fn: fn}
+}
+
+func (v *lazyValue) MarshalJSON() ([]byte, error) {
+	v.once.Do(func() {
+		v.value, v.err = json.Marshal(v.fn())
+	})
+	return v.value, v.err
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelFieldsUsed is set by the first LevelField, levelFieldCore passes
+// entries through untouched until then
+var levelFieldsUsed atomic.Bool
+
+// levelValue is the value of a LevelField, logged under key up to max
+type levelValue struct {
+	key   string
+	max   zapcore.Level
+	value interface{}
+}
+
+// LevelField returns a field that is only logged on entries at maxLevel or
+// below, e.g. the full SQL of a query on Debug entries but not on Info ones
+// logged from the same call site:
+//
+//	logger.InfoStruct("query", "table", t, logger.LevelField(zapcore.DebugLevel, "sql", q))
+//
+// It works in key-value pairs and With of the loggers of this package. Other
+// loggers and encoders skip it.
+func LevelField(maxLevel zapcore.Level, key string, value interface{}) zap.Field {
+	levelFieldsUsed.Store(true)
+	return zap.Field{Key: key, Type: zapcore.SkipType, Interface: levelValue{key: key, max: maxLevel, value: value}}
+}
+
+// levelFieldCore resolves LevelFields against the entry level. Fields added
+// with With are held back and resolved per entry as well.
+type levelFieldCore struct {
+	zapcore.Core
+	held []levelValue
+}
+
+func (c *levelFieldCore) With(fields []zapcore.Field) zapcore.Core {
+	held, rest := c.held, fields
+	if levelFieldsUsed.Load() {
+		rest = make([]zapcore.Field, 0, len(fields))
+		for _, f := range fields {
+			if v, ok := f.Interface.(levelValue); ok && f.Type == zapcore.SkipType {
+				held = append(held[:len(held):len(held)], v)
+				continue
+			}
+			rest = append(rest, f)
+		}
+	}
+	return &levelFieldCore{Core: c.Core.With(rest), held: held}
+}
+
+func (c *levelFieldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !levelFieldsUsed.Load() {
+		return c.Core.Check(ent, ce)
+	}
+
+	return deferredWrite(c.Core, ent, ce, c.resolve)
+}
+
+// resolve replaces the level fields of an entry and its logger by their
+// values at the entry's level, dropping those above their level
+func (c *levelFieldCore) resolve(ent zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+	resolved := make([]zapcore.Field, 0, len(fields)+len(c.held))
+	for _, v := range c.held {
+		if ent.Level <= v.max {
+			resolved = append(resolved, zap.Any(v.key, v.value))
+		}
+	}
+	for _, f := range fields {
+		if v, ok := f.Interface.(levelValue); ok && f.Type == zapcore.SkipType {
+			if ent.Level <= v.max {
+				resolved = append(resolved, zap.Any(v.key, v.value))
+			}
+			continue
+		}
+		resolved = append(resolved, f)
+	}
+	return resolved, true
+}
@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// allLevels lets every entry through. Sink cores use it because level gating
+// happens once in levelCore, above sampling.
+var allLevels = zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })
+
+// sinkLevel returns the enabler of a ConsoleLevel or FileLevel, which were
+// validated by resolveConfig, or nil when unset
+func sinkLevel(level string) zapcore.LevelEnabler {
+	if level == "" {
+		return nil
+	}
+	l, _ := zapcore.ParseLevel(level)
+	return l
+}
+
+// bothLevels enables the levels enabled by a and by b, a nil b enables all
+func bothLevels(a, b zapcore.LevelEnabler) zapcore.LevelEnabler {
+	if b == nil {
+		return a
+	}
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return a.Enabled(l) && b.Enabled(l)
+	})
+}
+
+// globalLevel is the level of the global logger, replaced on every Init
+var globalLevel = zap.NewAtomicLevel()
+
+var (
+	namedMu     sync.RWMutex
+	namedLevels = map[string]zap.AtomicLevel{}
+	namedMin    *zapcore.Level // lowest named level, nil when none is set
+)
+
+// SetLevel changes the level of the global logger at runtime
+func SetLevel(level zapcore.Level) {
+	ensureInitialized()
+	globalLevel.SetLevel(level)
+}
+
+// loggingDisabled is set between Disable and Enable
+var loggingDisabled atomic.Bool
+
+// Disable suppresses all entries of the package loggers, including named,
+// context and field level overrides and Audit, until Enable is called, e.g.
+// during a noisy maintenance window. The level set with SetLevel is kept.
+// It also applies before Init: the fallback logger writes nothing and
+// BufferPreInit holds nothing back for the real logger. The exception is Fatal: it is still written and exits the process, so a
+// crash is never swallowed. Panic entries still panic but are not written.
+func Disable() {
+	loggingDisabled.Store(true)
+}
+
+// Enable resumes logging after Disable at the levels in effect
+func Enable() {
+	loggingDisabled.Store(false)
+}
+
+// disabledAt reports whether Disable suppresses entries at l
+func disabledAt(l zapcore.Level) bool {
+	return l < zapcore.FatalLevel && loggingDisabled.Load()
+}
+
+// GetLevel returns the current level of the global logger
+func GetLevel() zapcore.Level {
+	ensureInitialized()
+	return globalLevel.Level()
+}
+
+// Named returns a logger for a component, logging with logger=name. Its
+// verbosity can be changed independently with SetNamedLevel.
+func Named(name string) *zap.SugaredLogger {
+	ensureInitialized()
+	// Returned for direct use, so drop the skip meant for the package functions
+	return globalLogger.Named(name).WithOptions(zap.AddCallerSkip(-1))
+}
+
+// SetNamedLevel sets the level for loggers with exactly this name, overriding
+// the global level in both directions: a named logger at Debug logs Debug
+// while the global level is Info, and one at Error stays quiet at Info.
+// Names of nested loggers are joined with dots, e.g. "http.client".
+func SetNamedLevel(name string, level zapcore.Level) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+
+	if l, ok := namedLevels[name]; ok {
+		l.SetLevel(level)
+	} else {
+		namedLevels[name] = zap.NewAtomicLevelAt(level)
+	}
+	updateNamedMin()
+}
+
+// ClearNamedLevel removes the override for name so it follows the global level again
+func ClearNamedLevel(name string) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+
+	delete(namedLevels, name)
+	updateNamedMin()
+}
+
+// updateNamedMin recomputes namedMin; the caller holds namedMu
+func updateNamedMin() {
+	namedMin = nil
+	for _, l := range namedLevels {
+		lvl := l.Level()
+		if namedMin == nil || lvl < *namedMin {
+			namedMin = &lvl
+		}
+	}
+}
+
+// levelCore gates entries by the level of their context, then of their
+// named logger, falling back to the logger's own level
+type levelCore struct {
+	zapcore.Core
+	level    zapcore.LevelEnabler
+	ctxLevel zapcore.LevelEnabler // set by ContextWithLevel, nil otherwise
+}
+
+func newLevelCore(core zapcore.Core, level zapcore.LevelEnabler) zapcore.Core {
+	return &levelCore{Core: core, level: level}
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	if disabledAt(l) {
+		return false
+	}
+	if c.level.Enabled(l) || c.ctxLevel != nil && c.ctxLevel.Enabled(l) {
+		return true
+	}
+	namedMu.RLock()
+	defer namedMu.RUnlock()
+	return namedMin != nil && l >= *namedMin
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return c.with(c.Core.With(fields), fields)
+}
+
+// with returns the gate for core, the result of adding fields to the gated core
+func (c *levelCore) with(core zapcore.Core, fields []zapcore.Field) *levelCore {
+	return &levelCore{Core: core, level: c.level, ctxLevel: contextLevelOf(fields, c.ctxLevel)}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if disabledAt(ent.Level) {
+		return ce
+	}
+	if c.levelFor(ent.LoggerName).Enabled(ent.Level) {
+		return c.Core.Check(ent, ce)
+	}
+	return ce
+}
+
+func (c *levelCore) levelFor(name string) zapcore.LevelEnabler {
+	if c.ctxLevel != nil {
+		return c.ctxLevel
+	}
+	if name == "" {
+		return c.level
+	}
+	namedMu.RLock()
+	defer namedMu.RUnlock()
+	if l, ok := namedLevels[name]; ok {
+		return l
+	}
+	return c.level
+}
+
+// contextLevelKey is the key of the field carrying the level of
+// ContextWithLevel to the level gates; it has SkipType so nothing is encoded
+const contextLevelKey = "logger.context_level"
+
+type contextLevelCtxKey struct{}
+
+// ContextWithLevel returns a context whose Ctx logging functions are evaluated
+// at level instead of the global or named level, e.g. to log Debug for a
+// single request flagged by a debug header while other traffic stays at Info.
+// SetLevelForField overrides still take precedence.
+func ContextWithLevel(ctx context.Context, level zapcore.Level) context.Context {
+	return context.WithValue(ctx, contextLevelCtxKey{}, level)
+}
+
+// contextLevelField returns the field passing the level of ctx to the level
+// gates, and false when ctx has none
+func contextLevelField(ctx context.Context) (zap.Field, bool) {
+	if ctx == nil {
+		return zap.Field{}, false
+	}
+	level, ok := ctx.Value(contextLevelCtxKey{}).(zapcore.Level)
+	if !ok {
+		return zap.Field{}, false
+	}
+	return zap.Field{Key: contextLevelKey, Type: zapcore.SkipType, Integer: int64(level)}, true
+}
+
+// contextLevelOf returns the context level carried by fields, or cur
+func contextLevelOf(fields []zapcore.Field, cur zapcore.LevelEnabler) zapcore.LevelEnabler {
+	for _, f := range fields {
+		if f.Type == zapcore.SkipType && f.Key == contextLevelKey {
+			cur = zapcore.Level(f.Integer)
+		}
+	}
+	return cur
+}
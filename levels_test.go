@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDisableFallbackLogger(t *testing.T) {
+	defer Snapshot()()
+	// The implicit Init fails without a service name
+	t.Setenv("SERVICE_NAME", "")
+	initialized = false
+	path := filepath.Join(t.TempDir(), "fallback.log")
+	cfg := zap.NewProductionConfig()
+	cfg.OutputPaths = []string{path}
+	cfg.Sampling = nil
+	SetFallbackConfig(cfg)
+
+	Disable()
+	Info("disabled entry")
+	Enable()
+	Info("enabled entry")
+	_ = Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "disabled entry") {
+		t.Errorf("fallback logger wrote an entry while disabled:\n%s", data)
+	}
+	if !strings.Contains(string(data), "enabled entry") {
+		t.Errorf("fallback logger lacks the entry after Enable:\n%s", data)
+	}
+}
+
+func TestDisableBufferPreInit(t *testing.T) {
+	defer Snapshot()()
+	initialized = false
+	BufferPreInit()
+
+	Disable()
+	Info("disabled entry")
+	Enable()
+	Info("enabled entry")
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	Use(zap.New(core))
+	if logs.FilterMessage("disabled entry").Len() != 0 {
+		t.Error("entry logged while disabled was replayed")
+	}
+	if logs.FilterMessage("enabled entry").Len() != 1 {
+		t.Errorf("entry logged after Enable was not replayed: %v", logs.All())
+	}
+}
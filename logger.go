@@ -5,23 +5,111 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/nglushkov/tp-logger/msgpack"
 )
 
 var globalLogger *zap.SugaredLogger
+var globalAudit *zap.SugaredLogger
+var globalConfig Config
+var globalStats *writeStats
+var globalCleanup func() error
 var initialized bool
 
+var (
+	traceIDMu        sync.RWMutex
+	traceIDGenerator func() string // nil selects the built-in IDs
+)
+
+// sessionTraceID is the session trace ID of the last Init, kept by the next
+var sessionTraceID string
+
+// Supported values for Config.Encoding
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack" // see the msgpack package for the schema and a decoder
+)
+
 // Config holds logger configuration
 type Config struct {
-	ServiceName      string                 // Optional: defaults to SERVICE_NAME env var
-	LogFile          string                 // Optional: defaults to /app/logs/{service}.log
-	Environment      string                 // Optional: defaults to APP_ENV or "dev"
-	Version          string                 // Optional: defaults to APP_VERSION or "1.0.0"
-	Console          bool                   // Optional: enable console output - defaults to true
-	AdditionalFields map[string]interface{} // Optional: additional fields to add to all logs
+	ServiceName           string                                          // Optional: defaults to SERVICE_NAME env var
+	LogFile               string                                          // Optional: defaults to /app/logs/{service}.log
+	Environment           string                                          // Optional: defaults to APP_ENV or "dev"
+	Version               string                                          // Optional: defaults to APP_VERSION or "1.0.0"
+	Console               bool                                            // Optional: enable console output - defaults to true
+	AdditionalFields      map[string]interface{}                          // Optional: additional fields to add to all logs
+	SortFields            bool                                            // Optional: sort JSON field keys alphabetically - defaults to false
+	AuditLogFile          string                                          // Optional: separate file receiving Audit entries - defaults to none
+	SplitStreams          bool                                            // Optional: console Info/Debug to stdout, Warn+ to stderr - defaults to false
+	WriteBufferKB         int                                             // Optional: buffer file writes in a buffer of this size - defaults to 0 (unbuffered)
+	FlushInterval         time.Duration                                   // Optional: how often the write buffer is flushed, also while idle - defaults to 1s
+	GlogStyle             bool                                            // Optional: per-severity files with symlinks, LogFile links to INFO - defaults to false
+	CloudLoggingFormat    bool                                            // Optional: Google Cloud Logging field names and severities - defaults to false
+	ExtraCores            []zapcore.Core                                  `json:"-"` // Optional: additional cores such as eventlog.Core, teed with the built-in sinks
+	MaxMessageBytes       int                                             // Optional: truncate longer messages - defaults to 0 (no limit)
+	TruncateFields        bool                                            // Optional: apply MaxMessageBytes to string field values too - defaults to false
+	DefaultTTL            time.Duration                                   // Optional: ttl_seconds attached to every entry, see WithTTL - defaults to 0 (no TTL)
+	MaxBufferBytes        int                                             // Optional: with WriteBufferKB, flush synchronously once this many bytes are pending - defaults to 0 (off)
+	DropOnPressure        bool                                            // Optional: drop Debug and Info entries while a forced flush fails, see DroppedEntryCount - defaults to false
+	WriteTimeout          time.Duration                                   // Optional: switch to FallbackLogFile while a LogFile write takes longer - defaults to 0 (off)
+	FallbackLogFile       string                                          // Optional: used while LogFile is stalled - defaults to LogFile + ".fallback"
+	Hostname              string                                          // Optional: value of the host field - defaults to LOG_HOSTNAME, then the detected hostname
+	CompactConsole        bool                                            // Optional: single-line "time LEVEL message k=v" console output without caller and stacktrace - defaults to false
+	ConsoleRateLimit      int                                             // Optional: console lines per second, excess lines are dropped and counted in console_suppressed - defaults to 0 (unlimited)
+	IDFormat              string                                          // Optional: format of generated trace IDs and NewID, one of the IDFormat constants - defaults to trace_{unix}_{n} trace IDs and base62 for NewID
+	SchemaVersion         string                                          // Optional: log_schema field for parsers to branch on, e.g. "1" - defaults to none (field omitted)
+	SamplingTick          time.Duration                                   // Optional: window after which sampling counts reset, longer windows drop more repeats - defaults to 1s
+	DisableSampling       bool                                            // Optional: write every entry instead of sampling repeated messages, e.g. for benchmarks - defaults to false
+	LevelEncoding         string                                          // Optional: "lower", "upper", "capital" or "number" for all encoders, ignored with CloudLoggingFormat - defaults to lower case, upper case in CompactConsole
+	IncludeSequence       bool                                            // Optional: strictly increasing seq field to detect lost entries, restarts at 1 with the process - defaults to false
+	CollapseRepeats       bool                                            // Optional: collapse back-to-back identical entries into "last message repeated N times", not applied to Audit - defaults to false
+	RedactKeys            []string                                        // Optional: keys whose values SetDefaultFieldsFromStruct logs as REDACTED, matched case-insensitively against the dotted key or its last part - defaults to none
+	CanceledLevel         string                                          // Optional: level of context.Canceled and context.DeadlineExceeded in ErrorUnexpected and ErrorMulti, "error" keeps them at Error - defaults to "info"
+	MaxBinaryBytes        int                                             // Optional: bytes of a Binary value that are logged, the rest is cut - defaults to 1024
+	LogStartupBanner      bool                                            // Optional: log the level, sinks and encoding at Info once Init completes - defaults to false
+	IncludeDevVCS         bool                                            // Optional: in the dev environment, add vcs_revision, vcs_time and vcs_modified from the build info - defaults to false
+	ConsoleErrorThreshold int                                             // Optional: stop console output after this many consecutive failed writes, e.g. to a closed stdout, and warn once in the other sinks; negative never stops - defaults to 10
+	FlattenFields         bool                                            // Optional: log nested objects, maps and structs as dotted keys such as user.id, up to 8 levels deep; arrays stay arrays - defaults to false
+	SocketPath            string                                          // Optional: also send JSON lines to this Unix domain socket, reconnecting with backoff and buffering up to 1 MiB while it is down; LogFile keeps every entry - defaults to none
+	TimedLevel            string                                          // Optional: level of the entries logged by Timed and TimedCtx, "debug", "info", "warn" or "error" - defaults to "debug"
+	MaxFields             int                                             // Optional: keep the first MaxFields fields of an entry and log the number dropped as fields_truncated, With fields are not counted - defaults to 0 (unlimited)
+	DisableFile           bool                                            // Optional: no LogFile sink, for console or socket only setups; GlogStyle and WriteTimeout need the file - defaults to false
+	ConsoleColor          bool                                            // Optional: color the level in CompactConsole output - defaults to false
+	EnvironmentPresets    bool                                            // Optional: fill unset sink fields from the Environment preset: dev compact colored console only, staging console and file, prod file plus LOG_SOCKET_PATH - defaults to false
+	DisableSessionTraceID bool                                            // Optional: omit the trace_id generated once per Init, so only Ctx functions log a trace_id, from the context - defaults to false
+	EnvelopeKey           string                                          // Optional: nest every JSON entry under this key, e.g. {"payload":{...}}; msgpack and CompactConsole output are not wrapped - defaults to none
+	EnvelopeFields        map[string]interface{}                          // Optional: static fields next to EnvelopeKey in the envelope, e.g. "source": "tp-logger" - defaults to none
+	SamplingStatsInterval time.Duration                                   // Optional: with sampling, log how many entries of each message were sampled out, at this interval and on Close - defaults to 0 (off)
+	AccessLogFormat       string                                          // Optional: "structured", or "common" or "combined" to also write NCSA log lines of Middleware to AccessLogFile - defaults to "structured"
+	AccessLogFile         string                                          // Optional: file receiving the NCSA access log lines - defaults to LogFile with the extension replaced by .access.log
+	PanicFormatter        func(msg string, fields []zapcore.Field) string `json:"-"` // Optional: builds the value Panic functions panic with from the message and the context and entry fields - defaults to the message
+	MinFreeDiskBytes      int64                                           // Optional: pause file writes while the file system of a log file has less free space, checked every 10s on Linux and macOS - defaults to 0 (off)
+	ConsoleLevel          string                                          // Optional: "debug", "info", "warn" or "error", minimum level of the console output on top of the global level - defaults to the global level
+	FileLevel             string                                          // Optional: "debug", "info", "warn" or "error", minimum level of LogFile or the GlogStyle files on top of the global level - defaults to the global level
+	TraceID               string                                          // Optional: session trace ID logged on every entry, see Init for the precedence - defaults to the ID of the previous Init, or a new one
+	Encoding              string                                          // Optional: "json" or "msgpack" for LogFile, GlogStyle and AuditLogFile, console stays JSON - defaults to "json"
+}
+
+// fallbackConfig replaces the development console logger used when the
+// implicit Init fails, see SetFallbackConfig
+var fallbackConfig *zap.Config
+
+// SetFallbackConfig sets the zap configuration of the logger used when a
+// package function is called before Init and the implicit Init with defaults
+// fails, e.g. zap.NewProductionConfig() for JSON on stdout a log collector can
+// parse. It must be called before the first log call to take effect. The
+// default is zap's development console logger on stdout.
+func SetFallbackConfig(cfg zap.Config) {
+	fallbackConfig = &cfg
 }
 
 // ensureInitialized initializes logger with defaults if not already done
@@ -38,19 +126,55 @@ func ensureInitialized() {
 		// If init fails, create minimal console-only logger
 		zapConfig := zap.NewDevelopmentConfig()
 		zapConfig.OutputPaths = []string{"stdout"}
-		logger, _ := zapConfig.Build()
-		globalLogger = logger.Sugar()
+		if fallbackConfig != nil {
+			zapConfig = *fallbackConfig
+		}
+		// Gated like the built loggers, so Disable applies
+		level := zapConfig.Level
+		logger, _ := zapConfig.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newLevelCore(core, level)
+		}))
+		globalLogger = capturable(logger, nil, true).Sugar()
+		globalAudit = globalLogger
+		globalCritical = globalLogger
+		globalLevel = zapConfig.Level
 	}
 
 	initialized = true
 }
 
-// generateTraceID creates a unique trace ID for this session
-func generateTraceID() string {
+// generateTraceID creates a unique trace ID using the installed generator, or
+// an ID of the given Config.IDFormat when none is installed
+func generateTraceID(format string) string {
+	traceIDMu.RLock()
+	gen := traceIDGenerator
+	traceIDMu.RUnlock()
+	switch {
+	case gen != nil:
+		return gen()
+	case format != "":
+		return newID(format)
+	default:
+		return defaultTraceID()
+	}
+}
+
+// defaultTraceID creates a unique trace ID for this session
+func defaultTraceID() string {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	return fmt.Sprintf("trace_%d_%d", time.Now().Unix(), r.Intn(10000))
 }
 
+// SetTraceIDGenerator replaces the trace ID generator, e.g. with a deterministic
+// one for golden-file tests. The generator is called once per Init for the
+// session trace_id, and by every helper that creates a trace ID for a context.
+// Passing nil restores the default generator, which honors Config.IDFormat.
+func SetTraceIDGenerator(fn func() string) {
+	traceIDMu.Lock()
+	defer traceIDMu.Unlock()
+	traceIDGenerator = fn
+}
+
 // getHostname returns the container hostname
 func getHostname() string {
 	hostname, err := os.Hostname()
@@ -60,49 +184,53 @@ func getHostname() string {
 	return hostname
 }
 
-// Init initializes the global logger with provided configuration
-func Init(cfg Config) error {
-	if cfg.ServiceName == "" {
-		cfg.ServiceName = os.Getenv("SERVICE_NAME")
-		if cfg.ServiceName == "" {
-			return fmt.Errorf("SERVICE_NAME environment variable is not set")
-		}
-	}
+// pipeline is a built logger together with the loggers derived from its cores
+type pipeline struct {
+	config        Config // fully resolved configuration
+	level         zap.AtomicLevel
+	newEncoder    func() zapcore.Encoder
+	initialFields []zap.Field
+	logger        *zap.Logger
+	audit         *zap.Logger // unsampled main core, teed with the audit file when configured
+	critical      *zap.Logger // unsampled main core
+	stats         *writeStats
+	sinks         map[string][]syncer // by sink name, see SyncSink
+	access        zapcore.WriteSyncer // NCSA access log file, nil when not configured
+	withService   func(name string, withoutTrace bool) *zap.Logger
+	traceless     *zap.Logger // global logger without the session trace ID, nil when there is none
+	cleanup       func() error
+}
 
-	if cfg.LogFile == "" {
-		cfg.LogFile = fmt.Sprintf("/app/logs/%s.log", cfg.ServiceName)
+// New builds a logger from the provided configuration without touching the
+// package globals. The returned cleanup function flushes and closes its sinks.
+func New(cfg Config) (*zap.SugaredLogger, func() error, error) {
+	p, err := build(cfg)
+	if err != nil {
+		return nil, nil, err
 	}
+	return p.logger.Sugar(), p.cleanup, nil
+}
 
-	// Set defaults
-	if cfg.Environment == "" {
-		cfg.Environment = os.Getenv("APP_ENV")
-		if cfg.Environment == "" {
-			cfg.Environment = "dev"
-		}
-	}
-	if cfg.Version == "" {
-		cfg.Version = os.Getenv("APP_VERSION")
-		if cfg.Version == "" {
-			cfg.Version = "1.0.0"
-		}
+// build resolves the configuration and constructs all cores and loggers
+func build(cfg Config) (*pipeline, error) {
+	cfg, err := resolveConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	config := zap.NewProductionConfig()
 
-	// Configure output paths
-	outputs := []string{}
-	if cfg.Console {
-		outputs = append(outputs, "stdout")
-	}
-	outputs = append(outputs, cfg.LogFile)
-	config.OutputPaths = outputs
-
 	// Configure encoder for readable logs
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
 	config.EncoderConfig.CallerKey = "caller"
 	config.EncoderConfig.MessageKey = "message"
 	config.EncoderConfig.LevelKey = "level"
+	if cfg.CloudLoggingFormat {
+		applyCloudLogging(&config.EncoderConfig)
+	} else {
+		config.EncoderConfig.EncodeLevel = levelEncoder(cfg.LevelEncoding)
+	}
 
 	// Development mode for dev environment
 	if cfg.Environment == "dev" {
@@ -110,13 +238,33 @@ func Init(cfg Config) error {
 		config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
 	}
 
+	if cfg.DisableSampling {
+		config.Sampling = nil
+	}
+
 	// Add default fields to ALL logs
 	config.InitialFields = map[string]interface{}{
-		"service":  cfg.ServiceName,
-		"env":      cfg.Environment,
-		"version":  cfg.Version,
-		"trace_id": generateTraceID(),
-		"host":     getHostname(),
+		"service": cfg.ServiceName,
+		"env":     cfg.Environment,
+		"version": cfg.Version,
+		"host":    cfg.Hostname,
+	}
+	// A session trace ID correlates all entries of a process run. Ctx entries
+	// with a trace ID of their own log that one in its place.
+	if !cfg.DisableSessionTraceID {
+		if cfg.TraceID == "" {
+			cfg.TraceID = generateTraceID(cfg.IDFormat)
+		}
+		traceKey, traceID := traceField(cfg, cfg.TraceID)
+		config.InitialFields[traceKey] = traceID
+	}
+	if cfg.SchemaVersion != "" {
+		config.InitialFields["log_schema"] = cfg.SchemaVersion
+	}
+	if cfg.IncludeDevVCS && cfg.Environment == "dev" {
+		for k, v := range vcsFields() {
+			config.InitialFields[k] = v
+		}
 	}
 
 	if cfg.AdditionalFields != nil {
@@ -125,22 +273,586 @@ func Init(cfg Config) error {
 		}
 	}
 
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	errSink, _, err := zap.Open(config.ErrorOutputPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build logger: %w", err)
+	}
+
+	envelope, err := newEnvelope(cfg)
+	if err != nil {
+		return nil, err
+	}
+	newEncoder := func() zapcore.Encoder {
+		var enc zapcore.Encoder = newHeaderEncoder(config.EncoderConfig, func(body zapcore.EncoderConfig) zapcore.Encoder {
+			if cfg.SortFields {
+				// Stable key order for golden files and diffs
+				return newSortedJSONEncoder(body)
+			}
+			return zapcore.NewJSONEncoder(body)
+		})
+		if envelope != nil {
+			enc = envelope(enc)
+		}
+		return enc
+	}
+
+	// File sinks may use the binary encoding, console output is always JSON
+	newFileEncoder := newEncoder
+	if cfg.Encoding == EncodingMsgpack {
+		newFileEncoder = func() zapcore.Encoder {
+			return msgpack.NewEncoder(config.EncoderConfig)
+		}
+	}
+
+	stats := &writeStats{}
+	wrapSinkCore := func(core zapcore.Core) zapcore.Core {
+		if cfg.MaxMessageBytes > 0 {
+			core = &truncateCore{Core: core, limit: cfg.MaxMessageBytes, fields: cfg.TruncateFields}
+		}
+		if cfg.FlattenFields {
+			core = &flattenCore{Core: core}
+		}
+		if cfg.MaxFields > 0 {
+			core = &maxFieldsCore{Core: core, limit: cfg.MaxFields}
+		}
+		return &statsCore{Core: core, stats: stats}
+	}
+	var limiter *consoleLimiter
+	if cfg.ConsoleRateLimit > 0 {
+		limiter = &consoleLimiter{limit: cfg.ConsoleRateLimit}
+		// Reported before the sinks are closed
+		closers = append([]func(){limiter.Close}, closers...)
+	}
+	// Per-sink levels; the global level gates all sinks before these
+	consoleLevel, fileLevel := sinkLevel(cfg.ConsoleLevel), sinkLevel(cfg.FileLevel)
+	newSinkCore := func(ws zapcore.WriteSyncer, enab zapcore.LevelEnabler, stream string) zapcore.Core {
+		enc := newEncoder()
+		if cfg.CompactConsole {
+			enc = newCompactEncoder(cfg.LevelEncoding, cfg.ConsoleColor)
+		}
+		core := newConsoleGuardCore(wrapSinkCore(zapcore.NewCore(enc, ws, bothLevels(enab, consoleLevel))), stream, cfg.ConsoleErrorThreshold)
+		if limiter != nil {
+			// The file log stays complete, only the console is throttled
+			core = newRateLimitCore(core, limiter)
+		}
+		return core
+	}
+	newFileCore := func(ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+		core := wrapSinkCore(zapcore.NewCore(newFileEncoder(), ws, enab))
+		if wd, ok := ws.(*watchdogSyncer); ok {
+			ws = wd.primary
+		}
+		if ps, ok := ws.(*pressureSyncer); ok {
+			core = &pressureCore{Core: core, sink: ps, stats: stats}
+		}
+		return core
+	}
+
+	var cores []zapcore.Core
+	sinks := map[string][]syncer{}
+	switch {
+	case cfg.DisableFile:
+	case cfg.GlogStyle:
+		glogCores, closeGlog, err := openGlogCores(cfg, func(ws zapcore.WriteSyncer, enab zapcore.LevelEnabler) zapcore.Core {
+			return newFileCore(ws, bothLevels(enab, fileLevel))
+		})
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, closeGlog)
+		cores = append(cores, glogCores...)
+		for _, c := range glogCores {
+			sinks[SinkFile] = append(sinks[SinkFile], c)
+		}
+	default:
+		fileSink, closeFile, err := openFileSink(cfg, cfg.LogFile)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, closeFile)
+		if cfg.WriteTimeout > 0 {
+			fallback, closeFallback, err := openFileSink(cfg, cfg.FallbackLogFile)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			closers = append(closers, closeFallback)
+			fileSink = &watchdogSyncer{
+				primary:  fileSink,
+				fallback: fallback,
+				timeout:  cfg.WriteTimeout,
+				stats:    stats,
+				path:     cfg.LogFile,
+				fallPath: cfg.FallbackLogFile,
+			}
+		}
+		cores = append(cores, newFileCore(fileSink, bothLevels(allLevels, fileLevel)))
+		sinks[SinkFile] = []syncer{fileSink}
+	}
+
+	if cfg.Console {
+		stdout, _, err := zap.Open("stdout")
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to build logger: %w", err)
+		}
+
+		if cfg.SplitStreams {
+			// Info/Debug to stdout, Warn and above to stderr
+			stderr, _, err := zap.Open("stderr")
+			if err != nil {
+				closeAll()
+				return nil, fmt.Errorf("failed to build logger: %w", err)
+			}
+			low := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return l < zap.WarnLevel
+			})
+			high := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+				return l >= zap.WarnLevel
+			})
+			cores = append(cores,
+				newSinkCore(stdout, low, SinkStdout),
+				newSinkCore(stderr, high, SinkStderr))
+			sinks[SinkStderr] = []syncer{stderr}
+		} else {
+			cores = append(cores, newSinkCore(stdout, allLevels, SinkStdout))
+		}
+		sinks[SinkStdout] = []syncer{stdout}
+	}
+
+	if cfg.SocketPath != "" {
+		// Newline-delimited JSON for the shipper, LogFile remains the durable copy
+		socket := &socketSyncer{path: cfg.SocketPath}
+		closers = append(closers, socket.Close)
+		cores = append(cores, wrapSinkCore(zapcore.NewCore(newEncoder(), socket, allLevels)))
+		sinks[SinkSocket] = []syncer{socket}
+	}
+
+	cores = append(cores, cfg.ExtraCores...)
+	for _, c := range cfg.ExtraCores {
+		sinks[SinkExtra] = append(sinks[SinkExtra], c)
+	}
+
+	unsampled := zapcore.NewTee(cores...)
+	core := unsampled
+	if config.Sampling != nil {
+		// Longer ticks dedupe steady low-rate repeats better but keep a burst
+		// suppressed for longer once Initial is used up
+		tick := cfg.SamplingTick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		var samplerOpts []zapcore.SamplerOption
+		if cfg.SamplingStatsInterval > 0 {
+			sampled := newSamplingStats(unsampled.With(sortedFields(config.InitialFields)), cfg.SamplingStatsInterval)
+			samplerOpts = append(samplerOpts, zapcore.SamplerHook(sampled.hook))
+			// Reported before the sinks are closed
+			closers = append([]func(){sampled.Close}, closers...)
+		}
+		core = zapcore.NewSamplerWithOptions(unsampled, tick, config.Sampling.Initial, config.Sampling.Thereafter, samplerOpts...)
+	}
+
+	// Audit entries bypass sampling and additionally go to the audit file,
+	// which is opened with the same options as LogFile
+	auditCore := unsampled
+	if cfg.AuditLogFile != "" {
+		auditSink, closeAudit, err := openFileSink(cfg, cfg.AuditLogFile)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, closeAudit)
+		sinks[SinkAudit] = []syncer{auditSink}
+		auditCore = zapcore.NewTee(unsampled, newFileCore(auditSink, zap.InfoLevel))
+	}
+
+	// Raw NCSA lines of Middleware, kept out of the structured sinks
+	var access zapcore.WriteSyncer
+	if cfg.AccessLogFormat != AccessLogStructured {
+		accessSink, closeAccess, err := openFileSink(cfg, cfg.AccessLogFile)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, closeAccess)
+		sinks[SinkAccess] = []syncer{accessSink}
+		access = accessSink
+	}
+
+	var seq *atomic.Uint64
+	if cfg.IncludeSequence {
+		seq = new(atomic.Uint64)
+	}
+
+	initialFields := sortedFields(config.InitialFields)
+	opts := buildOptions(config, errSink, initialFields)
+	// Collapsing goes first, so repeats are compared without seq and held back
+	// ones use up no number; the summary line gets seq and ttl like the others
+	mainCore := newGatedCore(newCollapseCore(newSeqCore(newTTLCore(core, cfg.DefaultTTL), seq), cfg.CollapseRepeats), config.Level)
+	p := &pipeline{
+		config:        cfg,
+		level:         config.Level,
+		newEncoder:    newEncoder,
+		initialFields: initialFields,
+		logger:        zap.New(mainCore, opts...),
+		audit:         zap.New(newGatedCore(newSeqCore(newTTLCore(auditCore, cfg.DefaultTTL), seq), config.Level), opts...),
+		critical:      zap.New(newGatedCore(newSeqCore(newTTLCore(unsampled, cfg.DefaultTTL), seq), config.Level), opts...),
+		stats:         stats,
+		sinks:         sinks,
+		access:        access,
+	}
+	// A logger of its own for other initial fields, they are already encoded
+	derive := func(fields []zap.Field) *zap.Logger {
+		overridden := *p
+		overridden.initialFields = fields
+		l := zap.New(mainCore, buildOptions(config, errSink, fields)...)
+		return l.WithOptions(zap.WrapCore(overridden.globalCore))
+	}
+	sessionKey, _ := traceField(cfg, "")
+	p.withService = func(name string, withoutTrace bool) *zap.Logger {
+		fields := replaceField(initialFields, zap.String("service", name))
+		if withoutTrace {
+			fields = removeField(fields, sessionKey)
+		}
+		return derive(fields)
+	}
+	if !cfg.DisableSessionTraceID {
+		p.traceless = derive(removeField(initialFields, sessionKey))
+	}
+	p.cleanup = func() error {
+		err := multierr.Append(p.logger.Sync(), p.audit.Sync())
+		if p.access != nil {
+			err = multierr.Append(err, p.access.Sync())
+		}
+		closeAll()
+		return err
+	}
+
+	return p, nil
+}
+
+// openFileSink opens a log file, wrapped in a write buffer when configured.
+// The returned function flushes the buffer and closes the file.
+func openFileSink(cfg Config, path string) (zapcore.WriteSyncer, func(), error) {
 	// Ensure log directory exists
-	logDir := filepath.Dir(cfg.LogFile)
+	if err := prepareLogFile(path); err != nil {
+		return nil, nil, err
+	}
+
+	sink, closeFile, err := zap.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file %q: %w", path, err)
+	}
+	if cfg.MinFreeDiskBytes > 0 {
+		// Below the buffer, so flushes are dropped as well while paused
+		guard := newDiskGuard(sink, path, cfg.MinFreeDiskBytes)
+		closeSink := closeFile
+		sink, closeFile = guard, func() {
+			guard.Close()
+			closeSink()
+		}
+	}
+	if cfg.WriteBufferKB <= 0 {
+		return sink, closeFile, nil
+	}
+
+	// Batch small writes to reduce IOPS; flushed periodically, on Sync and on
+	// Close. The flush ticker starts with the first write and keeps running
+	// while idle, so the last entry reaches the file within FlushInterval.
+	interval := cfg.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	buffered := &zapcore.BufferedWriteSyncer{
+		WS:            sink,
+		Size:          cfg.WriteBufferKB * 1024,
+		FlushInterval: interval,
+		Clock:         globalClock{},
+	}
+	closeBuffered := func() {
+		_ = buffered.Stop()
+		closeFile()
+	}
+	if cfg.MaxBufferBytes > 0 {
+		return &pressureSyncer{WriteSyncer: buffered, max: cfg.MaxBufferBytes, drop: cfg.DropOnPressure}, closeBuffered, nil
+	}
+	return buffered, closeBuffered, nil
+}
+
+// prepareLogFile validates a log file path and creates its parent directory
+func prepareLogFile(path string) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("log file path %q is a directory, set it to a file path such as %q",
+			path, filepath.Join(path, "app.log"))
+	}
+
+	logDir := filepath.Dir(path)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
+		return fmt.Errorf("failed to create log directory %q for log file %q: %w", logDir, path, err)
+	}
+	return nil
+}
+
+// resolveConfig fills in defaults from the environment and validates the configuration
+func resolveConfig(cfg Config) (Config, error) {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = os.Getenv("SERVICE_NAME")
+		if cfg.ServiceName == "" {
+			return cfg, fmt.Errorf("SERVICE_NAME environment variable is not set")
+		}
+	}
+
+	// Resolved first, the sink preset depends on it
+	if cfg.Environment == "" {
+		cfg.Environment = os.Getenv("APP_ENV")
+		if cfg.Environment == "" {
+			cfg.Environment = "dev"
+		}
+	}
+	if cfg.EnvironmentPresets {
+		cfg = applyPreset(cfg)
+	}
+
+	if cfg.LogFile == "" {
+		cfg.LogFile = fmt.Sprintf("/app/logs/%s.log", cfg.ServiceName)
+	}
+
+	if cfg.Hostname == "" {
+		cfg.Hostname = os.Getenv("LOG_HOSTNAME")
+		if cfg.Hostname == "" {
+			cfg.Hostname = getHostname()
+		}
+	}
+
+	if cfg.FallbackLogFile == "" {
+		cfg.FallbackLogFile = cfg.LogFile + ".fallback"
+	}
+
+	if cfg.MaxBinaryBytes <= 0 {
+		cfg.MaxBinaryBytes = defaultMaxBinaryBytes
+	}
+
+	if cfg.MinFreeDiskBytes < 0 {
+		return cfg, fmt.Errorf("min free disk bytes must not be negative, got %d", cfg.MinFreeDiskBytes)
+	}
+	if cfg.DefaultTTL < 0 {
+		return cfg, fmt.Errorf("default TTL must not be negative, got %s", cfg.DefaultTTL)
+	}
+
+	switch cfg.IDFormat {
+	case "", IDFormatUUID, IDFormatBase62, IDFormatKSUID, IDFormatULID:
+	default:
+		return cfg, fmt.Errorf("unknown ID format %q, use %q, %q, %q or %q",
+			cfg.IDFormat, IDFormatUUID, IDFormatBase62, IDFormatKSUID, IDFormatULID)
+	}
+
+	switch cfg.LevelEncoding {
+	case "", LevelEncodingLower, LevelEncodingUpper, LevelEncodingCapital, LevelEncodingNumber:
+	default:
+		return cfg, fmt.Errorf("unknown level encoding %q, use %q, %q, %q or %q", cfg.LevelEncoding,
+			LevelEncodingLower, LevelEncodingUpper, LevelEncodingCapital, LevelEncodingNumber)
+	}
+
+	switch cfg.CanceledLevel {
+	case "":
+		cfg.CanceledLevel = "info"
+	case "debug", "info", "warn", "error":
+	default:
+		return cfg, fmt.Errorf("unknown canceled level %q, use %q, %q, %q or %q", cfg.CanceledLevel, "debug", "info", "warn", "error")
+	}
+
+	switch cfg.TimedLevel {
+	case "":
+		cfg.TimedLevel = "debug"
+	case "debug", "info", "warn", "error":
+	default:
+		return cfg, fmt.Errorf("unknown timed level %q, use %q, %q, %q or %q", cfg.TimedLevel, "debug", "info", "warn", "error")
+	}
+
+	for _, level := range []string{cfg.ConsoleLevel, cfg.FileLevel} {
+		switch level {
+		case "", "debug", "info", "warn", "error":
+		default:
+			return cfg, fmt.Errorf("unknown sink level %q, use %q, %q, %q or %q", level, "debug", "info", "warn", "error")
+		}
+	}
+
+	switch cfg.Encoding {
+	case "":
+		cfg.Encoding = EncodingJSON
+	case EncodingJSON, EncodingMsgpack:
+	default:
+		return cfg, fmt.Errorf("unknown encoding %q, use %q or %q", cfg.Encoding, EncodingJSON, EncodingMsgpack)
 	}
 
-	logger, err := config.Build(zap.AddCallerSkip(1))
+	switch cfg.AccessLogFormat {
+	case "":
+		cfg.AccessLogFormat = AccessLogStructured
+	case AccessLogStructured, AccessLogCommon, AccessLogCombined:
+	default:
+		return cfg, fmt.Errorf("unknown access log format %q, use %q, %q or %q",
+			cfg.AccessLogFormat, AccessLogStructured, AccessLogCommon, AccessLogCombined)
+	}
+	if cfg.AccessLogFormat != AccessLogStructured && cfg.AccessLogFile == "" {
+		cfg.AccessLogFile = strings.TrimSuffix(cfg.LogFile, filepath.Ext(cfg.LogFile)) + ".access.log"
+	}
+
+	if cfg.DisableFile {
+		if cfg.GlogStyle || cfg.WriteTimeout > 0 {
+			return cfg, fmt.Errorf("GlogStyle and WriteTimeout require the file sink, unset DisableFile")
+		}
+		if !cfg.Console && cfg.SocketPath == "" && len(cfg.ExtraCores) == 0 {
+			return cfg, fmt.Errorf("no sinks left with DisableFile, enable Console, SocketPath or ExtraCores")
+		}
+	}
+
+	// Set defaults
+	if cfg.Version == "" {
+		cfg.Version = os.Getenv("APP_VERSION")
+		if cfg.Version == "" {
+			cfg.Version = "1.0.0"
+		}
+	}
+
+	return cfg, nil
+}
+
+// buildOptions mirrors the options zap.Config.Build derives from the config
+func buildOptions(config zap.Config, errSink zapcore.WriteSyncer, initialFields []zap.Field) []zap.Option {
+	opts := []zap.Option{zap.ErrorOutput(errSink), zap.AddCallerSkip(1), zap.WithClock(globalClock{})}
+
+	if config.Development {
+		opts = append(opts, zap.Development())
+	}
+	if !config.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+
+	stackLevel := zap.ErrorLevel
+	if config.Development {
+		stackLevel = zap.WarnLevel
+	}
+	if !config.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+
+	if len(initialFields) > 0 {
+		opts = append(opts, zap.Fields(initialFields...))
+	}
+
+	return opts
+}
+
+// sortedFields converts a field map into fields ordered by key
+func sortedFields(m map[string]interface{}) []zap.Field {
+	fields := make([]zap.Field, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		fields = append(fields, zap.Any(k, m[k]))
+	}
+	return fields
+}
+
+// Init initializes the global logger with provided configuration.
+//
+// The session trace ID is Config.TraceID when set. Otherwise the ID of the
+// previous Init is kept, so a reconfiguration mid-run does not break the
+// correlation with earlier entries, and only the first Init generates one.
+func Init(cfg Config) error {
+	if cfg.TraceID == "" {
+		cfg.TraceID = sessionTraceID
+	}
+	p, err := build(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to build logger: %w", err)
+		return err
 	}
 
-	globalLogger = logger.Sugar()
+	// Flush the previous logger; it is not closed since derived loggers may still use it
+	if globalLogger != nil {
+		_ = globalLogger.Sync()
+	}
+
+	internal = capturable(p.logger, p.initialFields, false).With(defaultFields...).Sugar()
+	globalLogger = capturable(p.logger.WithOptions(zap.WrapCore(p.globalCore)), p.initialFields, true).With(defaultFields...).Sugar()
+	globalAudit = capturable(p.audit.WithOptions(zap.WrapCore(p.globalCore)), p.initialFields, true).With(defaultFields...).Sugar()
+	globalCritical = capturable(p.critical.WithOptions(zap.WrapCore(p.globalCore)), p.initialFields, true).With(defaultFields...).Sugar()
+	globalTraceless = nil
+	if p.traceless != nil {
+		sessionKey, _ := traceField(p.config, "")
+		globalTraceless = capturable(p.traceless, removeField(p.initialFields, sessionKey), true).With(defaultFields...).Sugar()
+	}
+	globalStats = p.stats
+	// The new pipeline counts its write errors from zero
+	healthErrors.Store(0)
+	globalSinks = p.sinks
+	globalAccess = p.access
+	globalConfig = p.config
+	globalLevel = p.level
+	globalCleanup = p.cleanup
+	serviceLogger = p.withService
+	globalPipeline = p
+	usedLogger = false
+	if p.config.TraceID != "" {
+		sessionTraceID = p.config.TraceID
+	}
 	initialized = true
+	replayPreInit()
+
+	if p.config.LogStartupBanner {
+		logStartupBanner(p.config)
+	}
 	return nil
 }
 
+// logStartupBanner logs the effective settings, service, env and version are
+// already part of every entry
+func logStartupBanner(cfg Config) {
+	sinks := make([]string, 0, len(globalSinks))
+	for name := range globalSinks {
+		sinks = append(sinks, name)
+	}
+	sort.Strings(sinks)
+
+	kv := []interface{}{
+		"level", globalLevel.Level().String(),
+		"sinks", sinks,
+		"encoding", cfg.Encoding,
+	}
+	if !cfg.DisableFile {
+		kv = append(kv, "log_file", cfg.LogFile)
+	}
+	if cfg.AuditLogFile != "" {
+		kv = append(kv, "audit_log_file", cfg.AuditLogFile)
+	}
+	if cfg.AccessLogFormat != AccessLogStructured {
+		kv = append(kv, "access_log_file", cfg.AccessLogFile)
+	}
+	// Init may be reached through MustInit, so no caller is reported
+	globalLogger.WithOptions(zap.WithCaller(false)).Infow("logger initialized", kv...)
+}
+
+// Sync flushes any buffered log entries of the global logger
+func Sync() error {
+	ensureInitialized()
+	return globalLogger.Sync()
+}
+
+// Close flushes the global logger and closes its sinks
+func Close() error {
+	if globalCleanup == nil {
+		return nil
+	}
+	err := globalCleanup()
+	globalCleanup = nil
+	return err
+}
+
 // MustInit initializes logger and panics on error
 func MustInit(cfg Config) {
 	if err := Init(cfg); err != nil {
@@ -281,6 +993,14 @@ func WarnStruct(msg string, keysAndValues ...interface{}) {
 	globalLogger.Warnw(msg, keysAndValues...)
 }
 
+// Audit logs an audit event at Info level with an audit=true marker. Audit
+// entries are never sampled and are also written to Config.AuditLogFile.
+func Audit(action string, keysAndValues ...interface{}) {
+	ensureInitialized()
+
+	globalAudit.Infow(action, append([]interface{}{"audit", true}, keysAndValues...)...)
+}
+
 // Context logging - creates logger with additional fields
 func WithFields(keysAndValues ...interface{}) *zap.SugaredLogger {
 	return globalLogger.With(keysAndValues...)
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInitLogFileIsDirectory(t *testing.T) {
+	defer Snapshot()()
+	dir := t.TempDir()
+
+	err := Init(Config{ServiceName: "test", LogFile: dir})
+	if err == nil {
+		t.Fatal("Init with a directory as LogFile succeeded")
+	}
+	want := fmt.Sprintf("log file path %q is a directory, set it to a file path such as %q", dir, filepath.Join(dir, "app.log"))
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("Init error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestInitLogFileParentNotCreatable(t *testing.T) {
+	defer Snapshot()()
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(parent, "app.log")
+
+	err := Init(Config{ServiceName: "test", LogFile: path})
+	if err == nil {
+		t.Fatal("Init with a file as the log directory succeeded")
+	}
+	want := fmt.Sprintf("failed to create log directory %q for log file %q", parent, path)
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("Init error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestBufferedFileFlushedWhileIdle(t *testing.T) {
+	defer Snapshot()()
+	clk := newFakeClock()
+	SetClock(clk)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	err := Init(Config{ServiceName: "test", LogFile: path, WriteBufferKB: 64, FlushInterval: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	Info("last line before going idle")
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Fatalf("entry written before the flush interval: %s", data)
+	}
+
+	d, tick, ok := clk.Ticker(0)
+	if !ok {
+		t.Fatal("no flush ticker was started by the first write")
+	}
+	if d != 5*time.Second {
+		t.Fatalf("flush ticker interval = %v, want the FlushInterval 5s", d)
+	}
+	// No further writes: the interval passes idle
+	clk.Add(d)
+	tick <- clk.Now()
+
+	// The flush runs on the buffer's goroutine
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "last line before going idle") {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("entry not flushed within FlushInterval, file holds %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func BenchmarkFileSinkBuffered(b *testing.B) {
+	benchmarkFileSink(b, 256)
+}
+
+func BenchmarkFileSinkUnbuffered(b *testing.B) {
+	benchmarkFileSink(b, 0)
+}
+
+func benchmarkFileSink(b *testing.B, bufferKB int) {
+	defer Snapshot()()
+	path := filepath.Join(b.TempDir(), "app.log")
+	// Unsampled, every entry of the constant message reaches the file
+	if err := Init(Config{ServiceName: "bench", LogFile: path, WriteBufferKB: bufferKB, DisableSampling: true}); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		InfoStruct("order processed", "order_id", i, "status", "shipped")
+	}
+	b.StopTimer()
+
+	if err := Close(); err != nil {
+		b.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != b.N {
+		b.Fatalf("log file holds %d lines, want one per iteration, %d", lines, b.N)
+	}
+}
@@ -0,0 +1,74 @@
+// Package logrusbridge forwards logrus entries into the tp-logger pipeline,
+// so logrus call sites can be migrated incrementally.
+package logrusbridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	logger "github.com/nglushkov/tp-logger"
+)
+
+// traceIDKey is the logrus field carried over as the baggage trace ID
+const traceIDKey = "trace_id"
+
+type hook struct{}
+
+// LogrusHook returns a hook writing every logrus entry through the global
+// logger, with the entry's fields and the context fields of entry.Context.
+// A trace_id field is logged as the context trace ID. Levels map onto zap's:
+// Trace logs as Debug, and Fatal and Panic log as Error since logrus exits
+// or panics itself after running its hooks.
+//
+// To avoid duplicate output, point logrus at io.Discard once the hook is added:
+//
+//	logrus.AddHook(logrusbridge.LogrusHook())
+//	logrus.SetOutput(io.Discard)
+func LogrusHook() logrus.Hook {
+	return hook{}
+}
+
+func (hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kv := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		v := entry.Data[k]
+		if k == traceIDKey {
+			ctx = logger.ContextWithBaggage(ctx, logger.Baggage{TraceID: fmt.Sprint(v)})
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		kv = append(kv, k, v)
+	}
+
+	switch entry.Level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		logger.DebugCtx(ctx, entry.Message, kv...)
+	case logrus.InfoLevel:
+		logger.InfoCtx(ctx, entry.Message, kv...)
+	case logrus.WarnLevel:
+		logger.WarnCtx(ctx, entry.Message, kv...)
+	default:
+		logger.ErrorCtx(ctx, entry.Message, kv...)
+	}
+	return nil
+}
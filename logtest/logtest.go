@@ -0,0 +1,112 @@
+// Package logtest captures the entries of the logger package's global logger
+// in tests and asserts on them.
+//
+//	func TestCharge(t *testing.T) {
+//		logtest.Install(t)
+//		charge()
+//		logtest.RequireNoErrors(t)
+//		logtest.RequireLogged(t, zapcore.InfoLevel, "charged")
+//	}
+//
+// The global logger is shared by the whole process, so tests using this
+// package must not run in parallel.
+package logtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	logger "github.com/nglushkov/tp-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var (
+	mu     sync.Mutex
+	active *observer.ObservedLogs
+	owner  testing.TB
+)
+
+// Install replaces the global logger with one recording every entry, Trace
+// level included, for the rest of the test. The previous logger state is
+// restored when the test ends. The returned logs can be inspected directly.
+func Install(t testing.TB) *observer.ObservedLogs {
+	t.Helper()
+	restore := logger.Snapshot()
+	core, logs := observer.New(logger.TraceLevel)
+	logger.Use(zap.New(core))
+
+	mu.Lock()
+	active, owner = logs, t
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		if owner == t {
+			active, owner = nil, nil
+		}
+		mu.Unlock()
+		restore()
+	})
+	return logs
+}
+
+// RequireNoErrors fails the test if an entry at Error level or above was
+// logged since Install, listing those entries.
+func RequireNoErrors(t testing.TB) {
+	t.Helper()
+	logs := captured(t)
+	errs := logs.Filter(func(e observer.LoggedEntry) bool { return e.Level >= zapcore.ErrorLevel })
+	if errs.Len() > 0 {
+		t.Fatalf("expected no errors to be logged, got %d:\n%s", errs.Len(), describe(errs.All()))
+	}
+}
+
+// RequireLogged fails the test unless an entry at level whose message
+// contains substring was logged since Install, listing the captured entries.
+func RequireLogged(t testing.TB, level zapcore.Level, substring string) {
+	t.Helper()
+	logs := captured(t)
+	matching := logs.Filter(func(e observer.LoggedEntry) bool {
+		return e.Level == level && strings.Contains(e.Message, substring)
+	})
+	if matching.Len() == 0 {
+		t.Fatalf("expected a %s entry containing %q, got:\n%s", level, substring, describe(logs.All()))
+	}
+}
+
+func captured(t testing.TB) *observer.ObservedLogs {
+	t.Helper()
+	mu.Lock()
+	defer mu.Unlock()
+	if active == nil {
+		t.Fatal("logtest.Install was not called")
+	}
+	return active
+}
+
+// describe formats entries one per line as level, message and fields
+func describe(entries []observer.LoggedEntry) string {
+	if len(entries) == 0 {
+		return "  (no entries)"
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&b, "  %s %q", e.Level, e.Message)
+		fields := e.ContextMap()
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, " %s=%v", k, fields[k])
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
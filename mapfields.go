@@ -0,0 +1,60 @@
+package logger
+
+// InfoMap logs at Info with the entries of fields as individual fields, in
+// key order. Keys in Config.RedactKeys are logged as REDACTED, also in nested
+// maps; Config.FlattenFields applies like to any other field. A nil or empty
+// map logs the message alone.
+func InfoMap(msg string, fields map[string]interface{}) {
+	ensureInitialized()
+	globalLogger.Infow(msg, mapKeysAndValues(fields)...)
+}
+
+// DebugMap is InfoMap at Debug
+func DebugMap(msg string, fields map[string]interface{}) {
+	ensureInitialized()
+	globalLogger.Debugw(msg, mapKeysAndValues(fields)...)
+}
+
+// WarnMap is InfoMap at Warn
+func WarnMap(msg string, fields map[string]interface{}) {
+	ensureInitialized()
+	globalLogger.Warnw(msg, mapKeysAndValues(fields)...)
+}
+
+// ErrorMap is InfoMap at Error
+func ErrorMap(msg string, fields map[string]interface{}) {
+	ensureInitialized()
+	globalLogger.Errorw(msg, mapKeysAndValues(fields)...)
+}
+
+func mapKeysAndValues(fields map[string]interface{}) []interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	redact := globalConfig.RedactKeys
+	kv := make([]interface{}, 0, 2*len(fields))
+	for _, k := range sortedKeys(fields) {
+		kv = append(kv, k, redactMap(k, fields[k], redact))
+	}
+	return kv
+}
+
+// redactMap returns v with the values of redacted keys replaced, copying
+// nested maps rather than changing the caller's
+func redactMap(key string, v interface{}, redact []string) interface{} {
+	if len(redact) == 0 {
+		return v
+	}
+	if redactedKey(key, redact) {
+		return redactedValue
+	}
+	nested, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	out := make(map[string]interface{}, len(nested))
+	for k, nv := range nested {
+		out[k] = redactMap(key+"."+k, nv, redact)
+	}
+	return out
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// sensitiveHeaders are redacted even when allowlisted
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+type middlewareOptions struct {
+	headers []string // canonical names
+}
+
+// MiddlewareOption configures Middleware
+type MiddlewareOption func(*middlewareOptions)
+
+// LogHeaders logs the allowlisted request headers in a headers object.
+// Missing headers are omitted, repeated headers are joined with ", " and the
+// values of Authorization, Proxy-Authorization, Cookie and Set-Cookie are
+// redacted even when listed.
+func LogHeaders(allow []string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		for _, name := range allow {
+			o.headers = append(o.headers, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// Middleware logs every request with method, path, status and duration,
+// at Warn for 5xx responses and Info otherwise. Context fields of the
+// request's context are attached. With Config.AccessLogFormat common or
+// combined, an NCSA line is also written to Config.AccessLogFile.
+//
+// The trace of a W3C traceparent request header is continued: the handler's
+// context carries its trace ID as trace_id, its span ID as parent_span_id and
+// a new span_id, so LoggingRoundTripper passes the trace on to outbound
+// requests. Without a valid header a new trace is started, with an ID from
+// the SetTraceIDGenerator generator or in Config.IDFormat when either is set,
+// unless the context already has a trace ID.
+func Middleware(opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	var o middlewareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			r = r.WithContext(contextWithTraceparent(r.Context(), r.Header.Get(traceparentHeader)))
+			next.ServeHTTP(rec, r)
+
+			kv := []interface{}{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rec.status,
+				"duration", time.Since(start),
+			}
+			if headers := o.headerFields(r.Header); len(headers) > 0 {
+				kv = append(kv, zap.Any("headers", headers))
+			}
+
+			// Caller and stack would point at the middleware itself
+			l := ctxLogger(r.Context()).WithOptions(zap.WithCaller(false), zap.AddStacktrace(zap.FatalLevel+1))
+			if rec.status >= 500 {
+				l.Warnw("http request", kv...)
+			} else {
+				l.Infow("http request", kv...)
+			}
+			writeAccessLine(r, rec, start)
+		})
+	}
+}
+
+func (o *middlewareOptions) headerFields(h http.Header) map[string]string {
+	if len(o.headers) == 0 {
+		return nil
+	}
+	fields := make(map[string]string, len(o.headers))
+	for _, name := range o.headers {
+		values, ok := h[name]
+		if !ok {
+			continue
+		}
+		if sensitiveHeaders[name] {
+			fields[name] = redactedValue
+			continue
+		}
+		fields[name] = strings.Join(values, ", ")
+	}
+	return fields
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int // body bytes written
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
+
+// Flush forwards to the underlying writer so streaming handlers, e.g. server
+// sent events, keep working behind Middleware. It does nothing when the
+// writer cannot flush.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack forwards to the underlying writer for protocol upgrades such as
+// websockets
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer %T does not support hijacking: %w", r.ResponseWriter, http.ErrNotSupported)
+	}
+	return h.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMiddlewareLogHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		headers http.Header
+		want    map[string]string // nil when no headers field is logged
+	}{
+		{
+			name:    "single value",
+			allow:   []string{"X-Request-Id"},
+			headers: http.Header{"X-Request-Id": {"r-1"}},
+			want:    map[string]string{"X-Request-Id": "r-1"},
+		},
+		{
+			name:    "multi value joined",
+			allow:   []string{"accept"},
+			headers: http.Header{"Accept": {"text/html", "application/json"}},
+			want:    map[string]string{"Accept": "text/html, application/json"},
+		},
+		{
+			name:    "missing omitted",
+			allow:   []string{"X-Request-Id", "X-Tenant"},
+			headers: http.Header{"X-Tenant": {"acme"}},
+			want:    map[string]string{"X-Tenant": "acme"},
+		},
+		{
+			name:    "all missing",
+			allow:   []string{"X-Request-Id"},
+			headers: http.Header{"X-Tenant": {"acme"}},
+			want:    nil,
+		},
+		{
+			name:  "sensitive redacted",
+			allow: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+			headers: http.Header{
+				"Authorization":       {"Bearer secret"},
+				"Proxy-Authorization": {"Basic c2VjcmV0"},
+				"Cookie":              {"session=1", "theme=dark"},
+				"Set-Cookie":          {"session=2"},
+			},
+			want: map[string]string{
+				"Authorization":       redactedValue,
+				"Proxy-Authorization": redactedValue,
+				"Cookie":              redactedValue,
+				"Set-Cookie":          redactedValue,
+			},
+		},
+		{
+			name:    "not allowlisted",
+			allow:   nil,
+			headers: http.Header{"X-Request-Id": {"r-1"}},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Snapshot()()
+			core, logs := observer.New(zapcore.DebugLevel)
+			Use(zap.New(core))
+
+			handler := Middleware(LogHeaders(tt.allow))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			for name, values := range tt.headers {
+				r.Header[name] = values
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			entries := logs.FilterMessage("http request").All()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d http request entries, want 1", len(entries))
+			}
+			got, ok := entries[0].ContextMap()["headers"]
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("headers = %v, want no headers field", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("headers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewareKeepsFlusherAndHijacker(t *testing.T) {
+	defer Snapshot()()
+	core, logs := observer.New(zapcore.DebugLevel)
+	Use(zap.New(core))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("response writer behind Middleware is not an http.Flusher")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: first\n\n")
+		f.Flush()
+	})
+	mux.HandleFunc("/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("response writer behind Middleware is not an http.Hijacker")
+			return
+		}
+		conn, buf, err := h.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		buf.Flush()
+	})
+	// A hijacked request is logged after the client got its response, and
+	// the server does not wait for it on Close
+	var served sync.WaitGroup
+	served.Add(2)
+	handler := Middleware()(mux)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer served.Done()
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "data: first\n\n" {
+		t.Errorf("event stream body = %q", body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/upgrade", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("upgrade status = %d, want 101", resp.StatusCode)
+	}
+
+	served.Wait()
+	if n := logs.FilterMessage("http request").Len(); n != 2 {
+		t.Errorf("logged %d http request entries, want 2", n)
+	}
+}
+
+func TestStatusRecorderHijackUnsupported(t *testing.T) {
+	rec := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	if _, _, err := rec.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Fatalf("Hijack error = %v, want http.ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,196 @@
+package msgpack
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Decoder reads MessagePack log entries from a stream
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder creates a decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next entry. It returns io.EOF when the stream ends cleanly
+// and io.ErrUnexpectedEOF when it ends inside an entry.
+//
+// Integers decode as int64 or uint64, floats as float64, strings as string,
+// binary data as []byte, arrays as []interface{} and maps as
+// map[string]interface{}. Extension values decode as []byte.
+func (d *Decoder) Decode() (map[string]interface{}, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	v, err := d.value()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("msgpack: entry is %T, not a map", v)
+	}
+	return m, nil
+}
+
+func (d *Decoder) value() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.mapOf(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.arrayOf(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+
+	switch c {
+	case fmtNil:
+		return nil, nil
+	case fmtFalse:
+		return false, nil
+	case fmtTrue:
+		return true, nil
+	case fmtBin8, fmtBin16, fmtBin32:
+		n, err := d.length(c, fmtBin8)
+		if err != nil {
+			return nil, err
+		}
+		return d.bytes(n)
+	case fmtExt8, fmtExt16, fmtExt32:
+		n, err := d.length(c, fmtExt8)
+		if err != nil {
+			return nil, err
+		}
+		return d.bytes(n + 1) // type byte and data
+	case fmtFixExt1, fmtFixExt2, fmtFixExt4, fmtFixExt8, fmtFixExt16:
+		sizes := map[byte]int{fmtFixExt1: 1, fmtFixExt2: 2, fmtFixExt4: 4, fmtFixExt8: 8, fmtFixExt16: 16}
+		return d.bytes(sizes[c] + 1)
+	case fmtFloat32:
+		b, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case fmtFloat64:
+		b, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case fmtUint8, fmtUint16, fmtUint32, fmtUint64:
+		b, err := d.bytes(1 << (c - fmtUint8))
+		if err != nil {
+			return nil, err
+		}
+		return beUint(b), nil
+	case fmtInt8, fmtInt16, fmtInt32, fmtInt64:
+		size := 1 << (c - fmtInt8)
+		b, err := d.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		u := beUint(b)
+		shift := 64 - 8*uint(size)
+		return int64(u<<shift) >> shift, nil
+	case fmtStr8, fmtStr16, fmtStr32:
+		n, err := d.length(c, fmtStr8)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case fmtArray16, fmtArray32:
+		n, err := d.length(c-fmtArray16+1, 0)
+		if err != nil {
+			return nil, err
+		}
+		return d.arrayOf(n)
+	case fmtMap16, fmtMap32:
+		n, err := d.length(c-fmtMap16+1, 0)
+		if err != nil {
+			return nil, err
+		}
+		return d.mapOf(n)
+	}
+	return nil, fmt.Errorf("msgpack: unknown format byte 0x%02x", c)
+}
+
+// length reads a big-endian length whose size is 1, 2 or 4 bytes depending on
+// how far c is from the 8-bit variant base
+func (d *Decoder) length(c, base byte) (int, error) {
+	b, err := d.bytes(1 << (c - base))
+	if err != nil {
+		return 0, err
+	}
+	return int(beUint(b)), nil
+}
+
+func (d *Decoder) bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d *Decoder) str(n int) (string, error) {
+	b, err := d.bytes(n)
+	return string(b), err
+}
+
+func (d *Decoder) arrayOf(n int) ([]interface{}, error) {
+	out := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (d *Decoder) mapOf(n int) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+func beUint(b []byte) uint64 {
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u
+}
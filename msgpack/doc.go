@@ -0,0 +1,20 @@
+// Package msgpack implements a zap encoder writing MessagePack and a decoder
+// for reading such logs back.
+//
+// Every entry is a single MessagePack map; entries are written back to back
+// without separators. The standard fields use the keys of the
+// zapcore.EncoderConfig and are encoded as:
+//
+//	level       string, as produced by EncodeLevel (e.g. "info")
+//	timestamp   as produced by EncodeTime, int64 Unix nanoseconds when unset
+//	logger      string, only for named loggers
+//	caller      string, as produced by EncodeCaller (e.g. "pkg/file.go:42")
+//	function    string, when FunctionKey is set
+//	message     string
+//	stacktrace  string, last key, only when a stack was captured
+//
+// Context fields follow the message in the order they were added. Durations
+// use EncodeDuration (int64 nanoseconds when unset), complex numbers are
+// two-element float arrays, namespaces become nested maps and reflected values
+// are converted through their JSON representation.
+package msgpack
@@ -0,0 +1,497 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var bufferPool = buffer.NewPool()
+
+// frame holds the encoded key-value pairs of one map
+type frame struct {
+	key string // namespace key, empty for the root
+	buf []byte
+	n   int // number of pairs
+}
+
+// Encoder is a zapcore.Encoder producing one MessagePack map per entry
+type Encoder struct {
+	cfg     *zapcore.EncoderConfig
+	frames  []frame      // frames[0] holds the context fields, the rest are open namespaces
+	scratch arrayEncoder // encodes single values, see scratchArray
+	out     []byte       // entry being assembled by a pooled encoder
+}
+
+// NewEncoder creates a MessagePack encoder using the keys and encoders of cfg.
+// LineEnding is ignored since MessagePack entries are self-delimiting.
+func NewEncoder(cfg zapcore.EncoderConfig) *Encoder {
+	return &Encoder{cfg: &cfg, frames: []frame{{}}}
+}
+
+func (e *Encoder) current() *frame {
+	return &e.frames[len(e.frames)-1]
+}
+
+// key writes a map key into the current frame and returns it for the value
+func (e *Encoder) key(k string) *frame {
+	f := e.current()
+	f.buf = appendString(f.buf, k)
+	f.n++
+	return f
+}
+
+func (e *Encoder) addRaw(k string, v []byte) {
+	f := e.key(k)
+	f.buf = append(f.buf, v...)
+}
+
+// closeNamespaces folds open namespaces into their parents as nested maps
+func (e *Encoder) closeNamespaces() {
+	for len(e.frames) > 1 {
+		ns := e.frames[len(e.frames)-1]
+		e.frames = e.frames[:len(e.frames)-1]
+		f := e.key(ns.key)
+		f.buf = appendMapHeader(f.buf, ns.n)
+		f.buf = append(f.buf, ns.buf...)
+	}
+}
+
+func (e *Encoder) Clone() zapcore.Encoder {
+	c := &Encoder{cfg: e.cfg, frames: make([]frame, len(e.frames))}
+	for i, f := range e.frames {
+		c.frames[i] = frame{key: f.key, buf: append([]byte(nil), f.buf...), n: f.n}
+	}
+	return c
+}
+
+func (e *Encoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := getEncoder(e)
+	defer putEncoder(final)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	final.closeNamespaces()
+
+	cfg := e.cfg
+	root := final.frames[0]
+	hasStack := ent.Stack != "" && cfg.StacktraceKey != ""
+	n := headerPairs(cfg, ent) + root.n
+	if hasStack {
+		n++
+	}
+
+	out := appendMapHeader(final.out[:0], n)
+	if cfg.LevelKey != "" && cfg.EncodeLevel != nil {
+		out = appendString(out, cfg.LevelKey)
+		out = final.appendEncoded(out, ent.Level.String(), func(a zapcore.PrimitiveArrayEncoder) { cfg.EncodeLevel(ent.Level, a) })
+	}
+	if cfg.TimeKey != "" && !ent.Time.IsZero() {
+		a := final.scratchArray()
+		a.AppendTime(ent.Time)
+		out = append(appendString(out, cfg.TimeKey), a.single()...)
+	}
+	if ent.LoggerName != "" && cfg.NameKey != "" {
+		nameEncoder := cfg.EncodeName
+		if nameEncoder == nil {
+			nameEncoder = zapcore.FullNameEncoder
+		}
+		out = appendString(out, cfg.NameKey)
+		out = final.appendEncoded(out, ent.LoggerName, func(a zapcore.PrimitiveArrayEncoder) { nameEncoder(ent.LoggerName, a) })
+	}
+	if ent.Caller.Defined {
+		if cfg.CallerKey != "" && cfg.EncodeCaller != nil {
+			out = appendString(out, cfg.CallerKey)
+			out = final.appendEncoded(out, ent.Caller.String(), func(a zapcore.PrimitiveArrayEncoder) { cfg.EncodeCaller(ent.Caller, a) })
+		}
+		if cfg.FunctionKey != "" {
+			out = appendString(appendString(out, cfg.FunctionKey), ent.Caller.Function)
+		}
+	}
+	if cfg.MessageKey != "" {
+		out = appendString(appendString(out, cfg.MessageKey), ent.Message)
+	}
+	out = append(out, root.buf...)
+	if hasStack {
+		out = appendString(out, cfg.StacktraceKey)
+		out = appendString(out, ent.Stack)
+	}
+	final.out = out
+
+	buf := bufferPool.Get()
+	buf.Write(out)
+	return buf, nil
+}
+
+// headerPairs returns the number of standard fields EncodeEntry writes for
+// ent, the stacktrace excluded
+func headerPairs(cfg *zapcore.EncoderConfig, ent zapcore.Entry) int {
+	n := 0
+	if cfg.LevelKey != "" && cfg.EncodeLevel != nil {
+		n++
+	}
+	if cfg.TimeKey != "" && !ent.Time.IsZero() {
+		n++
+	}
+	if ent.LoggerName != "" && cfg.NameKey != "" {
+		n++
+	}
+	if ent.Caller.Defined {
+		if cfg.CallerKey != "" && cfg.EncodeCaller != nil {
+			n++
+		}
+		if cfg.FunctionKey != "" {
+			n++
+		}
+	}
+	if cfg.MessageKey != "" {
+		n++
+	}
+	return n
+}
+
+// maxPooledBytes bounds the buffers kept by pooled encoders, so a single huge
+// entry does not pin its memory
+const maxPooledBytes = 64 << 10
+
+// encoderPool holds the per-entry encoders of EncodeEntry
+var encoderPool = sync.Pool{New: func() interface{} { return &Encoder{} }}
+
+// getEncoder returns a pooled encoder holding a copy of the fields of e,
+// reusing the buffers of its previous use
+func getEncoder(e *Encoder) *Encoder {
+	c := encoderPool.Get().(*Encoder)
+	c.cfg = e.cfg
+	c.scratch.cfg = e.cfg
+	frames := c.frames[:0]
+	for i, f := range e.frames {
+		var buf []byte
+		if i < cap(frames) {
+			buf = frames[:i+1][i].buf[:0]
+		}
+		frames = append(frames, frame{key: f.key, buf: append(buf, f.buf...), n: f.n})
+	}
+	c.frames = frames
+	return c
+}
+
+func putEncoder(c *Encoder) {
+	if cap(c.out) > maxPooledBytes {
+		return
+	}
+	for _, f := range c.frames[:cap(c.frames)] {
+		if cap(f.buf) > maxPooledBytes {
+			return
+		}
+	}
+	c.cfg, c.scratch.cfg = nil, nil
+	encoderPool.Put(c)
+}
+
+// scratchArray returns the reset scratch array encoder of e, whose buffer is
+// only valid until its next use
+func (e *Encoder) scratchArray() *arrayEncoder {
+	if e.scratch.cfg == nil {
+		e.scratch.cfg = e.cfg
+	}
+	e.scratch.buf, e.scratch.n = e.scratch.buf[:0], 0
+	return &e.scratch
+}
+
+// appendEncoded appends the value produced by a zap value encoder, an array
+// if it produced several, or fallback if it produced none
+func (e *Encoder) appendEncoded(b []byte, fallback string, encode func(zapcore.PrimitiveArrayEncoder)) []byte {
+	a := e.scratchArray()
+	encode(a)
+	switch a.n {
+	case 0:
+		return appendString(b, fallback)
+	case 1:
+		return append(b, a.buf...)
+	default:
+		return append(appendArrayHeader(b, a.n), a.buf...)
+	}
+}
+
+func (e *Encoder) OpenNamespace(k string) {
+	e.frames = append(e.frames, frame{key: k})
+}
+
+func (e *Encoder) AddArray(k string, v zapcore.ArrayMarshaler) error {
+	a := &arrayEncoder{cfg: e.cfg}
+	err := v.MarshalLogArray(a)
+	e.addRaw(k, append(appendArrayHeader(nil, a.n), a.buf...))
+	return err
+}
+
+func (e *Encoder) AddObject(k string, v zapcore.ObjectMarshaler) error {
+	obj, err := encodeObject(e.cfg, v)
+	e.addRaw(k, obj)
+	return err
+}
+
+func (e *Encoder) AddReflected(k string, v interface{}) error {
+	b, err := encodeReflected(v)
+	if err != nil {
+		return err
+	}
+	e.addRaw(k, b)
+	return nil
+}
+
+func (e *Encoder) AddBinary(k string, v []byte) {
+	f := e.key(k)
+	f.buf = appendBinary(f.buf, v)
+}
+
+func (e *Encoder) AddByteString(k string, v []byte) {
+	f := e.key(k)
+	f.buf = appendByteString(f.buf, v)
+}
+
+func (e *Encoder) AddBool(k string, v bool) {
+	f := e.key(k)
+	f.buf = appendBool(f.buf, v)
+}
+
+func (e *Encoder) AddComplex128(k string, v complex128) {
+	f := e.key(k)
+	f.buf = appendComplex(f.buf, real(v), imag(v))
+}
+
+func (e *Encoder) AddComplex64(k string, v complex64) {
+	f := e.key(k)
+	f.buf = appendComplex(f.buf, float64(real(v)), float64(imag(v)))
+}
+
+func (e *Encoder) AddDuration(k string, v time.Duration) {
+	a := e.scratchArray()
+	a.AppendDuration(v)
+	e.addRaw(k, a.single())
+}
+
+func (e *Encoder) AddFloat64(k string, v float64) {
+	f := e.key(k)
+	f.buf = appendFloat64(f.buf, v)
+}
+
+func (e *Encoder) AddFloat32(k string, v float32) {
+	f := e.key(k)
+	f.buf = appendFloat32(f.buf, v)
+}
+
+func (e *Encoder) AddInt(k string, v int)     { e.AddInt64(k, int64(v)) }
+func (e *Encoder) AddInt32(k string, v int32) { e.AddInt64(k, int64(v)) }
+func (e *Encoder) AddInt16(k string, v int16) { e.AddInt64(k, int64(v)) }
+func (e *Encoder) AddInt8(k string, v int8)   { e.AddInt64(k, int64(v)) }
+
+func (e *Encoder) AddInt64(k string, v int64) {
+	f := e.key(k)
+	f.buf = appendInt(f.buf, v)
+}
+
+func (e *Encoder) AddString(k string, v string) {
+	f := e.key(k)
+	f.buf = appendString(f.buf, v)
+}
+
+func (e *Encoder) AddTime(k string, v time.Time) {
+	a := e.scratchArray()
+	a.AppendTime(v)
+	e.addRaw(k, a.single())
+}
+
+func (e *Encoder) AddUint(k string, v uint)       { e.AddUint64(k, uint64(v)) }
+func (e *Encoder) AddUint32(k string, v uint32)   { e.AddUint64(k, uint64(v)) }
+func (e *Encoder) AddUint16(k string, v uint16)   { e.AddUint64(k, uint64(v)) }
+func (e *Encoder) AddUint8(k string, v uint8)     { e.AddUint64(k, uint64(v)) }
+func (e *Encoder) AddUintptr(k string, v uintptr) { e.AddUint64(k, uint64(v)) }
+
+func (e *Encoder) AddUint64(k string, v uint64) {
+	f := e.key(k)
+	f.buf = appendUint(f.buf, v)
+}
+
+// encodeObject encodes an ObjectMarshaler as a map
+func encodeObject(cfg *zapcore.EncoderConfig, v zapcore.ObjectMarshaler) ([]byte, error) {
+	obj := &Encoder{cfg: cfg, frames: []frame{{}}}
+	err := v.MarshalLogObject(obj)
+	obj.closeNamespaces()
+	return append(appendMapHeader(nil, obj.frames[0].n), obj.frames[0].buf...), err
+}
+
+// encodeReflected converts v through its JSON representation
+func encodeReflected(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return appendGeneric(nil, generic), nil
+}
+
+// appendGeneric encodes a value decoded from JSON. Map keys are sorted so the
+// output is deterministic.
+func appendGeneric(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return appendNil(b)
+	case bool:
+		return appendBool(b, v)
+	case string:
+		return appendString(b, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendInt(b, i)
+		}
+		f, _ := v.Float64()
+		return appendFloat64(b, f)
+	case []interface{}:
+		b = appendArrayHeader(b, len(v))
+		for _, item := range v {
+			b = appendGeneric(b, item)
+		}
+		return b
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendMapHeader(b, len(v))
+		for _, k := range keys {
+			b = appendString(b, k)
+			b = appendGeneric(b, v[k])
+		}
+		return b
+	default:
+		return appendString(b, fmt.Sprint(v))
+	}
+}
+
+// arrayEncoder collects array elements
+type arrayEncoder struct {
+	cfg *zapcore.EncoderConfig
+	buf []byte
+	n   int
+}
+
+// single returns the only element, or all elements as an array
+func (a *arrayEncoder) single() []byte {
+	if a.n == 1 {
+		return a.buf
+	}
+	return append(appendArrayHeader(nil, a.n), a.buf...)
+}
+
+func (a *arrayEncoder) AppendArray(v zapcore.ArrayMarshaler) error {
+	sub := &arrayEncoder{cfg: a.cfg}
+	err := v.MarshalLogArray(sub)
+	a.buf = append(appendArrayHeader(a.buf, sub.n), sub.buf...)
+	a.n++
+	return err
+}
+
+func (a *arrayEncoder) AppendObject(v zapcore.ObjectMarshaler) error {
+	obj, err := encodeObject(a.cfg, v)
+	a.buf = append(a.buf, obj...)
+	a.n++
+	return err
+}
+
+func (a *arrayEncoder) AppendReflected(v interface{}) error {
+	b, err := encodeReflected(v)
+	if err != nil {
+		return err
+	}
+	a.buf = append(a.buf, b...)
+	a.n++
+	return nil
+}
+
+func (a *arrayEncoder) AppendDuration(v time.Duration) {
+	before := a.n
+	if a.cfg.EncodeDuration != nil {
+		a.cfg.EncodeDuration(v, a)
+	}
+	if a.n == before {
+		a.AppendInt64(int64(v))
+	}
+}
+
+func (a *arrayEncoder) AppendTime(v time.Time) {
+	before := a.n
+	if a.cfg.EncodeTime != nil {
+		a.cfg.EncodeTime(v, a)
+	}
+	if a.n == before {
+		a.AppendInt64(v.UnixNano())
+	}
+}
+
+func (a *arrayEncoder) AppendBool(v bool) {
+	a.buf = appendBool(a.buf, v)
+	a.n++
+}
+
+func (a *arrayEncoder) AppendByteString(v []byte) {
+	a.buf = appendByteString(a.buf, v)
+	a.n++
+}
+
+func (a *arrayEncoder) AppendComplex128(v complex128) {
+	a.buf = appendComplex(a.buf, real(v), imag(v))
+	a.n++
+}
+
+func (a *arrayEncoder) AppendComplex64(v complex64) {
+	a.buf = appendComplex(a.buf, float64(real(v)), float64(imag(v)))
+	a.n++
+}
+
+func (a *arrayEncoder) AppendFloat64(v float64) {
+	a.buf = appendFloat64(a.buf, v)
+	a.n++
+}
+
+func (a *arrayEncoder) AppendFloat32(v float32) {
+	a.buf = appendFloat32(a.buf, v)
+	a.n++
+}
+
+func (a *arrayEncoder) AppendInt(v int)     { a.AppendInt64(int64(v)) }
+func (a *arrayEncoder) AppendInt32(v int32) { a.AppendInt64(int64(v)) }
+func (a *arrayEncoder) AppendInt16(v int16) { a.AppendInt64(int64(v)) }
+func (a *arrayEncoder) AppendInt8(v int8)   { a.AppendInt64(int64(v)) }
+
+func (a *arrayEncoder) AppendInt64(v int64) {
+	a.buf = appendInt(a.buf, v)
+	a.n++
+}
+
+func (a *arrayEncoder) AppendString(v string) {
+	a.buf = appendString(a.buf, v)
+	a.n++
+}
+
+func (a *arrayEncoder) AppendUint(v uint)       { a.AppendUint64(uint64(v)) }
+func (a *arrayEncoder) AppendUint32(v uint32)   { a.AppendUint64(uint64(v)) }
+func (a *arrayEncoder) AppendUint16(v uint16)   { a.AppendUint64(uint64(v)) }
+func (a *arrayEncoder) AppendUint8(v uint8)     { a.AppendUint64(uint64(v)) }
+func (a *arrayEncoder) AppendUintptr(v uintptr) { a.AppendUint64(uint64(v)) }
+
+func (a *arrayEncoder) AppendUint64(v uint64) {
+	a.buf = appendUint(a.buf, v)
+	a.n++
+}
@@ -0,0 +1,108 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEncodeEntryConcurrent(t *testing.T) {
+	enc := NewEncoder(zap.NewProductionEncoderConfig())
+	enc.AddString("service", "orders")
+	enc.OpenNamespace("ctx")
+	enc.AddInt("shard", 3)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Unix(1700000000, 0), Message: "reused"}
+				buf, err := enc.EncodeEntry(ent, []zapcore.Field{
+					zap.Int("worker", g),
+					zap.Duration("elapsed", time.Duration(i)),
+					zap.Namespace("detail"),
+					zap.String("pad", string(bytes.Repeat([]byte("x"), i))),
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got, err := NewDecoder(bytes.NewReader(buf.Bytes())).Decode()
+				buf.Free()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				want := map[string]interface{}{
+					"level":   "warn",
+					"ts":      float64(1700000000),
+					"msg":     "reused",
+					"service": "orders",
+					"ctx": map[string]interface{}{
+						"shard":   int64(3),
+						"worker":  int64(g),
+						"elapsed": float64(i) / float64(time.Second),
+						"detail":  map[string]interface{}{"pad": string(bytes.Repeat([]byte("x"), i))},
+					},
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("decoded %v, want %v", got, want)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkEncodeEntry(b *testing.B) {
+	cfg := zap.NewProductionEncoderConfig()
+	ent := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		Message: "order processed",
+		Caller:  zapcore.NewEntryCaller(0, "/src/orders/process.go", 42, true),
+	}
+	context := []zapcore.Field{
+		zap.String("service", "orders"),
+		zap.String("env", "prod"),
+		zap.String("trace_id", "0af7651916cd43dd8448eb211c80319c"),
+	}
+	fields := []zapcore.Field{
+		zap.Int("order_id", 1234),
+		zap.String("status", "shipped"),
+		zap.Float64("amount", 99.95),
+		zap.Duration("elapsed", 42*time.Millisecond),
+		zap.Bool("priority", true),
+	}
+
+	for _, bb := range []struct {
+		name string
+		enc  zapcore.Encoder
+	}{
+		{"msgpack", NewEncoder(cfg)},
+		{"json", zapcore.NewJSONEncoder(cfg)},
+	} {
+		enc := bb.enc.Clone()
+		for _, f := range context {
+			f.AddTo(enc)
+		}
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf, err := enc.EncodeEntry(ent, fields)
+				if err != nil {
+					b.Fatal(err)
+				}
+				buf.Free()
+			}
+		})
+	}
+}
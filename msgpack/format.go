@@ -0,0 +1,154 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// MessagePack format bytes
+const (
+	fmtNil      = 0xc0
+	fmtFalse    = 0xc2
+	fmtTrue     = 0xc3
+	fmtBin8     = 0xc4
+	fmtBin16    = 0xc5
+	fmtBin32    = 0xc6
+	fmtExt8     = 0xc7
+	fmtExt16    = 0xc8
+	fmtExt32    = 0xc9
+	fmtFloat32  = 0xca
+	fmtFloat64  = 0xcb
+	fmtUint8    = 0xcc
+	fmtUint16   = 0xcd
+	fmtUint32   = 0xce
+	fmtUint64   = 0xcf
+	fmtInt8     = 0xd0
+	fmtInt16    = 0xd1
+	fmtInt32    = 0xd2
+	fmtInt64    = 0xd3
+	fmtFixExt1  = 0xd4
+	fmtFixExt2  = 0xd5
+	fmtFixExt4  = 0xd6
+	fmtFixExt8  = 0xd7
+	fmtFixExt16 = 0xd8
+	fmtStr8     = 0xd9
+	fmtStr16    = 0xda
+	fmtStr32    = 0xdb
+	fmtArray16  = 0xdc
+	fmtArray32  = 0xdd
+	fmtMap16    = 0xde
+	fmtMap32    = 0xdf
+)
+
+func appendNil(b []byte) []byte {
+	return append(b, fmtNil)
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, fmtTrue)
+	}
+	return append(b, fmtFalse)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, fmtInt8, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, fmtInt16), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, fmtInt32), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, fmtInt64), uint64(v))
+	}
+}
+
+func appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, fmtUint8, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, fmtUint16), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, fmtUint32), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, fmtUint64), v)
+	}
+}
+
+func appendFloat32(b []byte, v float32) []byte {
+	return binary.BigEndian.AppendUint32(append(b, fmtFloat32), math.Float32bits(v))
+}
+
+func appendFloat64(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, fmtFloat64), math.Float64bits(v))
+}
+
+func appendStringHeader(b []byte, n int) []byte {
+	switch {
+	case n < 32:
+		return append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, fmtStr8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, fmtStr16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, fmtStr32), uint32(n))
+	}
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendStringHeader(b, len(s)), s...)
+}
+
+func appendByteString(b []byte, s []byte) []byte {
+	return append(appendStringHeader(b, len(s)), s...)
+}
+
+func appendBinary(b []byte, v []byte) []byte {
+	n := len(v)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, fmtBin8, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, fmtBin16), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, fmtBin32), uint32(n))
+	}
+	return append(b, v...)
+}
+
+func appendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, fmtArray16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, fmtArray32), uint32(n))
+	}
+}
+
+func appendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, fmtMap16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, fmtMap32), uint32(n))
+	}
+}
+
+func appendComplex(b []byte, real, imag float64) []byte {
+	b = appendArrayHeader(b, 2)
+	b = appendFloat64(b, real)
+	return appendFloat64(b, imag)
+}
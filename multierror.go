@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrorMulti logs errs at Error with every error as its own field, error_0,
+// error_1, ..., plus error_count. Joined errors (errors.Join, or anything with
+// Unwrap() []error or WrappedErrors() []error such as go-multierror) are
+// flattened; nil errors are skipped, so an empty result logs error_count=0.
+// If every error is a context cancellation or deadline, the entry is logged
+// at Config.CanceledLevel instead.
+func ErrorMulti(msg string, errs []error, keysAndValues ...interface{}) {
+	ensureInitialized()
+
+	flat := flattenErrors(nil, errs)
+	kv := make([]interface{}, 0, 2*len(flat)+2+len(keysAndValues))
+	for i, err := range flat {
+		kv = append(kv, "error_"+strconv.Itoa(i), err.Error())
+	}
+	kv = append(kv, "error_count", len(flat))
+
+	level := zapcore.ErrorLevel
+	if len(flat) > 0 && allCanceled(flat) {
+		level = canceledLevel()
+	}
+	globalLogger.Logw(level, msg, append(kv, keysAndValues...)...)
+}
+
+func flattenErrors(dst []error, errs []error) []error {
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+		case interface{ Unwrap() []error }:
+			dst = flattenErrors(dst, e.Unwrap())
+		case interface{ WrappedErrors() []error }:
+			dst = flattenErrors(dst, e.WrappedErrors())
+		default:
+			dst = append(dst, err)
+		}
+	}
+	return dst
+}
+
+func allCanceled(errs []error) bool {
+	for _, err := range errs {
+		if !isCanceled(err) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// panicCore panics with the value of Config.PanicFormatter once a Panic entry
+// has been written. zap's own panic hook only sees the entry fields, so the
+// context fields are tracked here to give the formatter e.g. the trace_id.
+type panicCore struct {
+	zapcore.Core
+	format func(msg string, fields []zapcore.Field) string
+	fields []zapcore.Field // initial and With fields
+}
+
+func (c *panicCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return &panicCore{Core: c.Core.With(fields), format: c.format, fields: append(merged, fields...)}
+}
+
+func (c *panicCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level != zapcore.PanicLevel {
+		return c.Core.Check(ent, ce)
+	}
+	// Added last so the other cores of ce write first; a filtered entry
+	// still panics
+	return ce.AddCore(ent, &panicWrite{Core: c.Core, format: c.format, fields: c.fields, downstream: c.Core.Check(ent, nil)})
+}
+
+// panicWrite is the per-entry core added by panicCore.Check
+type panicWrite struct {
+	zapcore.Core
+	format     func(msg string, fields []zapcore.Field) string
+	fields     []zapcore.Field
+	downstream *zapcore.CheckedEntry // nil when the entry was dropped
+}
+
+func (w *panicWrite) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	writeDownstream(w.downstream, ent, fields)
+	all := make([]zapcore.Field, 0, len(w.fields)+len(fields))
+	all = append(all, w.fields...)
+	// Preempts zap's own panic with the bare message
+	panic(w.format(ent.Message, append(all, fields...)))
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// preInitBufferCap is the number of entries BufferPreInit holds; later ones
+// are dropped and counted
+const preInitBufferCap = 1000
+
+type bufferedEntry struct {
+	ent    zapcore.Entry
+	fields []zapcore.Field
+}
+
+// preInitBuffer holds the entries logged before Init
+type preInitBuffer struct {
+	mu       sync.Mutex
+	entries  []bufferedEntry
+	dropped  int
+	replayed bool
+}
+
+var preInit *preInitBuffer
+
+// BufferPreInit makes the package functions hold entries in memory until Init
+// or Use installs the real logger, which then writes them with their original
+// time and caller, subject to its level and filters. This keeps startup
+// diagnostics logged before Init from going through the implicit default
+// logger. Up to 1000 entries are kept; a warning reports any dropped beyond
+// that. Loggers derived before Init keep working and log through the real
+// logger afterwards.
+//
+// Call it first thing in main. It has no effect once a logger is installed.
+// Entries stay in memory if Init is never called, except that a Fatal or
+// Panic entry writes the buffer to stderr before the process ends.
+func BufferPreInit() {
+	if initialized {
+		return
+	}
+	preInit = &preInitBuffer{}
+	// Entries suppressed by Disable are not buffered for the real logger
+	core := newLevelCore(&preInitCore{buf: preInit}, allLevels)
+	l := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.ErrorLevel))
+	internal = capturable(l, nil, false).Sugar()
+	globalLogger = capturable(l, nil, true).Sugar()
+	globalAudit = globalLogger
+	globalCritical = globalLogger
+	initialized = true
+}
+
+// replayPreInit writes the buffered entries through the global logger just
+// installed by Init or Use
+func replayPreInit() {
+	buf := preInit
+	if buf == nil {
+		return
+	}
+	preInit = nil
+
+	buf.mu.Lock()
+	entries, dropped := buf.entries, buf.dropped
+	buf.entries, buf.replayed = nil, true
+	buf.mu.Unlock()
+
+	core := globalLogger.Desugar().Core()
+	for _, e := range entries {
+		if ce := core.Check(e.ent, nil); ce != nil {
+			ce.Write(e.fields...)
+		}
+	}
+	if dropped > 0 {
+		InternalLogger().Warnw("dropped log entries written before Init", "dropped", dropped)
+	}
+}
+
+// preInitCore buffers entries until the real logger is installed, then
+// forwards to it
+type preInitCore struct {
+	buf    *preInitBuffer
+	fields []zapcore.Field // context fields
+}
+
+func (c *preInitCore) Enabled(zapcore.Level) bool { return true }
+
+func (c *preInitCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return &preInitCore{buf: c.buf, fields: append(merged, fields...)}
+}
+
+func (c *preInitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *preInitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(append(all, c.fields...), fields...)
+
+	c.buf.mu.Lock()
+	if c.buf.replayed {
+		c.buf.mu.Unlock()
+		// A logger derived before Init
+		if ce := globalLogger.Desugar().Core().Check(ent, nil); ce != nil {
+			ce.Write(all...)
+		}
+		return nil
+	}
+	if len(c.buf.entries) < preInitBufferCap {
+		c.buf.entries = append(c.buf.entries, bufferedEntry{ent: ent, fields: all})
+	} else {
+		c.buf.dropped++
+	}
+	c.buf.mu.Unlock()
+
+	if ent.Level > zapcore.ErrorLevel {
+		// The process may end before Init, so don't lose the startup logs
+		c.buf.dumpToStderr()
+	}
+	return nil
+}
+
+func (c *preInitCore) Sync() error { return nil }
+
+// dumpToStderr writes the buffered entries as JSON to stderr
+func (b *preInitBuffer) dumpToStderr() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	errSink, _, err := zap.Open("stderr")
+	if err != nil {
+		return
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), errSink, allLevels)
+	for _, e := range b.entries {
+		_ = core.Write(e.ent, e.fields)
+	}
+	b.entries = nil
+	_ = core.Sync()
+}
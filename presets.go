@@ -0,0 +1,35 @@
+package logger
+
+import "os"
+
+// Sink presets applied with Config.EnvironmentPresets:
+//
+//	Environment  console                        file     network
+//	dev          on, CompactConsole and color   off      -
+//	staging      on                             on       -
+//	prod         as configured                  on       SocketPath from LOG_SOCKET_PATH
+//
+// Other environments get no preset. Precedence, highest first: fields set in
+// Config, then environment variables, then the preset. A preset only fills
+// fields left at their zero value, so it can turn a feature on but never off:
+// an explicit LogFile keeps the file in dev and Console: true keeps the
+// console in prod. A preset that enables a flag, such as the dev console,
+// cannot be undone through Config; leave EnvironmentPresets off instead.
+func applyPreset(cfg Config) Config {
+	switch cfg.Environment {
+	case "dev":
+		cfg.Console = true
+		cfg.CompactConsole = true
+		cfg.ConsoleColor = true
+		if cfg.LogFile == "" && !cfg.GlogStyle {
+			cfg.DisableFile = true
+		}
+	case "staging":
+		cfg.Console = true
+	case "prod":
+		if cfg.SocketPath == "" {
+			cfg.SocketPath = os.Getenv("LOG_SOCKET_PATH")
+		}
+	}
+	return cfg
+}
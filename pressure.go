@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// pressureSyncer bounds the memory held by a buffered file sink: once
+// MaxBufferBytes were written since the last forced flush, the buffer is flushed
+// synchronously. Periodic flushes by the buffer itself are not observed, so a
+// forced flush may come earlier than strictly needed, never later.
+type pressureSyncer struct {
+	zapcore.WriteSyncer
+	max  int
+	drop bool // drop low-priority entries while flushing fails
+
+	mu      sync.Mutex
+	pending int
+	stalled bool // the last forced flush failed
+}
+
+func (s *pressureSyncer) Write(p []byte) (int, error) {
+	n, err := s.WriteSyncer.Write(p)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending += n
+	if s.pending >= s.max {
+		s.stalled = s.WriteSyncer.Sync() != nil
+		if !s.stalled {
+			s.pending = 0
+		}
+	}
+	return n, err
+}
+
+func (s *pressureSyncer) Sync() error {
+	err := s.WriteSyncer.Sync()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.pending = 0
+		s.stalled = false
+	}
+	return err
+}
+
+// shed reports whether an entry at level should be dropped to relieve the buffer
+func (s *pressureSyncer) shed(level zapcore.Level) bool {
+	if !s.drop || level >= zap.WarnLevel {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stalled
+}
+
+// pressureCore drops Debug and Info entries while its sink can't be flushed
+type pressureCore struct {
+	zapcore.Core
+	sink  *pressureSyncer
+	stats *writeStats
+}
+
+func (c *pressureCore) With(fields []zapcore.Field) zapcore.Core {
+	return &pressureCore{Core: c.Core.With(fields), sink: c.sink, stats: c.stats}
+}
+
+func (c *pressureCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *pressureCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if c.sink.shed(ent.Level) {
+		c.stats.dropped.Add(1)
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// DroppedEntryCount returns how many entries the global logger dropped under
+// memory pressure, see Config.DropOnPressure
+func DroppedEntryCount() uint64 {
+	if globalStats == nil {
+		return 0
+	}
+	return globalStats.dropped.Load()
+}
@@ -0,0 +1,29 @@
+// Package query filters JSON log files, such as those written by the logger
+// package, by predicates over their fields.
+//
+// An expression combines comparisons of fields with constants:
+//
+//	expr        = and { "||" and }
+//	and         = unary { "&&" unary }
+//	unary       = "!" unary | "(" expr ")" | comparison
+//	comparison  = field [ op value ]
+//	op          = "==" | "!=" | "<" | "<=" | ">" | ">="
+//	value       = string | number | "true" | "false" | "null" | word
+//
+// A field is a key such as tenant_id, with dots selecting nested objects:
+// http.status matches a top-level "http.status" key first, then the status
+// key inside an http object. A field without an operator tests that the key
+// is present. Strings are double quoted with JSON escapes; a bare word is a
+// string too, so level>=error needs no quotes.
+//
+// Numbers compare numerically and strings lexically, except for the level
+// and severity fields, which compare by severity: trace < debug < info <
+// notice < warn < error < dpanic < panic < fatal, case-insensitively, with
+// Cloud Logging names such as WARNING and CRITICAL mapped accordingly.
+// Comparisons of values of different types, and of missing fields, are
+// false, "!=" included; use !field to select entries without a key.
+//
+// Example:
+//
+//	level>=error && tenant_id=="acme" && !(message=="health check")
+package query
@@ -0,0 +1,360 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// node is a compiled expression
+type node interface {
+	match(entry map[string]interface{}) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(e map[string]interface{}) bool { return n.left.match(e) || n.right.match(e) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(e map[string]interface{}) bool { return n.left.match(e) && n.right.match(e) }
+
+type notNode struct{ inner node }
+
+func (n notNode) match(e map[string]interface{}) bool { return !n.inner.match(e) }
+
+type existsNode struct{ field string }
+
+func (n existsNode) match(e map[string]interface{}) bool {
+	_, ok := lookup(e, n.field)
+	return ok
+}
+
+type compareNode struct {
+	field string
+	op    string
+	value interface{} // string, float64, bool or nil
+}
+
+func (n compareNode) match(e map[string]interface{}) bool {
+	v, ok := lookup(e, n.field)
+	if !ok {
+		return false
+	}
+	if isLevelField(n.field) {
+		if got, ok := levelRank(v); ok {
+			if want, ok := levelRank(n.value); ok {
+				return compareOrdered(got, want, n.op)
+			}
+		}
+	}
+
+	switch want := n.value.(type) {
+	case nil:
+		return compareEqual(v == nil, n.op)
+	case bool:
+		got, ok := v.(bool)
+		return ok && compareEqual(got == want, n.op)
+	case float64:
+		num, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		got, err := num.Float64()
+		return err == nil && compareOrdered(got, want, n.op)
+	case string:
+		got, ok := v.(string)
+		return ok && compareOrdered(strings.Compare(got, want), 0, n.op)
+	}
+	return false
+}
+
+func compareEqual(equal bool, op string) bool {
+	switch op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	}
+	return false
+}
+
+func compareOrdered[T int | float64](a, b T, op string) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func isLevelField(field string) bool {
+	return field == "level" || field == "severity"
+}
+
+// levelRanks orders the level names of zap and Cloud Logging
+var levelRanks = map[string]int{
+	"trace":     0,
+	"debug":     1,
+	"default":   2,
+	"info":      2,
+	"notice":    3,
+	"warn":      4,
+	"warning":   4,
+	"error":     5,
+	"dpanic":    6,
+	"critical":  6,
+	"panic":     7,
+	"alert":     7,
+	"fatal":     8,
+	"emergency": 8,
+}
+
+// levelRank returns the severity of a level name or zap level number
+func levelRank(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case string:
+		rank, ok := levelRanks[strings.ToLower(v)]
+		return rank, ok
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return levelNumberRank(n)
+	case float64:
+		return levelNumberRank(int64(v))
+	}
+	return 0, false
+}
+
+// levelNumberRanks maps zap's numeric levels, -2 (trace) to 5 (fatal)
+var levelNumberRanks = map[int64]int{-2: 0, -1: 1, 0: 2, 1: 4, 2: 5, 3: 6, 4: 7, 5: 8}
+
+func levelNumberRank(n int64) (int, bool) {
+	rank, ok := levelNumberRanks[n]
+	return rank, ok
+}
+
+// parser is a recursive descent parser over the tokens of an expression
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func parse(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			t := p.peek()
+			return nil, fmt.Errorf("expected ) at offset %d", t.pos)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	field := p.next()
+	if field.kind != tokWord {
+		return nil, fmt.Errorf("expected field name at offset %d", field.pos)
+	}
+	op := p.peek()
+	if op.kind != tokOp || !isComparison(op.text) {
+		return existsNode{field: field.text}, nil
+	}
+	p.pos++
+
+	t := p.next()
+	n := compareNode{field: field.text, op: op.text}
+	switch t.kind {
+	case tokString:
+		n.value = t.text
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		n.value = f
+	case tokWord:
+		switch t.text {
+		case "true":
+			n.value = true
+		case "false":
+			n.value = false
+		case "null":
+			n.value = nil
+		default:
+			n.value = t.text
+		}
+	default:
+		return nil, fmt.Errorf("expected value at offset %d", t.pos)
+	}
+	return n, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			s, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, pos: i})
+			i = end + 1
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="),
+			strings.HasPrefix(expr[i:], "<="), strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{kind: tokOp, text: expr[i : i+2], pos: i})
+			i += 2
+		case c == '<' || c == '>' || c == '!' || c == '(' || c == ')':
+			tokens = append(tokens, token{kind: tokOp, text: expr[i : i+1], pos: i})
+			i++
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(expr) && strings.IndexByte("0123456789.eE+-", expr[end]) >= 0 {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: expr[i:end], pos: i})
+			i = end
+		case isWordByte(c):
+			end := i + 1
+			for end < len(expr) && isWordByte(expr[end]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokWord, text: expr[i:end], pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(expr)}), nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == '@' || c == '/' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MalformedLinesError is returned by Filter after all input was processed
+// when some lines were not JSON objects. Those lines are skipped.
+type MalformedLinesError struct {
+	Count int
+}
+
+func (e *MalformedLinesError) Error() string {
+	return fmt.Sprintf("skipped %d malformed log lines", e.Count)
+}
+
+// Filter copies the lines of r matching expr to w, unchanged. Empty lines are
+// ignored. expr is parsed before anything is read; see the package
+// documentation for its grammar.
+func Filter(r io.Reader, w io.Writer, expr string) error {
+	q, err := parse(expr)
+	if err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	malformed := 0
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			entry, ok := decodeLine(trimmed)
+			switch {
+			case !ok:
+				malformed++
+			case q.match(entry):
+				if _, err := bw.Write(trimmed); err != nil {
+					return fmt.Errorf("failed to write log line: %w", err)
+				}
+				if err := bw.WriteByte('\n'); err != nil {
+					return fmt.Errorf("failed to write log line: %w", err)
+				}
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read log: %w", readErr)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write log line: %w", err)
+	}
+	if malformed > 0 {
+		return &MalformedLinesError{Count: malformed}
+	}
+	return nil
+}
+
+func decodeLine(line []byte) (map[string]interface{}, bool) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var entry map[string]interface{}
+	if err := dec.Decode(&entry); err != nil || entry == nil || dec.More() {
+		return nil, false
+	}
+	return entry, true
+}
+
+// lookup returns the value of a dotted field, preferring a flat key
+func lookup(entry map[string]interface{}, field string) (interface{}, bool) {
+	if v, ok := entry[field]; ok {
+		return v, true
+	}
+	head, rest, found := strings.Cut(field, ".")
+	if !found {
+		return nil, false
+	}
+	nested, ok := entry[head].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookup(nested, rest)
+}
@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// consoleLimiter allows a number of console lines per second, shared by all
+// console cores of a pipeline. Windows follow the package clock. While lines
+// are suppressed a ticker reports the count of every ended window, so the
+// summary does not wait for the next line.
+type consoleLimiter struct {
+	limit int
+	cores []zapcore.Core // console cores without context fields, receiving the summary
+
+	mu         sync.Mutex
+	window     int64 // current second, unix
+	count      int
+	suppressed int
+	stop       chan struct{} // closed to stop the ticker, nil while it is not running
+	done       chan struct{} // closed when the ticker goroutine returned
+}
+
+// allow reports whether a line may be written now, and how many lines were
+// suppressed in the previous window if it just ended
+func (l *consoleLimiter) allow() (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ended := l.roll(clock().Now())
+	if l.count >= l.limit {
+		l.suppressed++
+		if l.stop == nil {
+			l.stop, l.done = make(chan struct{}), make(chan struct{})
+			go l.run(clock().NewTicker(time.Second), l.stop, l.done)
+		}
+		return false, ended
+	}
+	l.count++
+	return true, ended
+}
+
+// roll starts a new window once now is past the current one and returns the
+// lines suppressed in the window that ended. Called with mu held.
+func (l *consoleLimiter) roll(now time.Time) int {
+	sec := now.Unix()
+	if sec == l.window {
+		return 0
+	}
+	ended := l.suppressed
+	l.window, l.count, l.suppressed = sec, 0, 0
+	return ended
+}
+
+// run reports ended windows every second until stopped, or until a window
+// passes without suppressed lines
+func (l *consoleLimiter) run(ticker *time.Ticker, stop, done chan struct{}) {
+	defer close(done)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := clock().Now()
+			l.mu.Lock()
+			ended := l.roll(now)
+			idle := ended == 0 && l.suppressed == 0 && l.stop == stop
+			if idle {
+				l.stop, l.done = nil, nil
+			}
+			l.mu.Unlock()
+			if idle {
+				return
+			}
+			if ended > 0 {
+				l.writeSummary(now, ended)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// flush stops the ticker and returns the lines suppressed so far
+func (l *consoleLimiter) flush() int {
+	l.mu.Lock()
+	stop, done := l.stop, l.done
+	l.stop, l.done = nil, nil
+	suppressed := l.suppressed
+	l.suppressed = 0
+	l.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	return suppressed
+}
+
+// Close reports the lines suppressed so far and stops the ticker
+func (l *consoleLimiter) Close() {
+	if suppressed := l.flush(); suppressed > 0 {
+		l.writeSummary(clock().Now(), suppressed)
+	}
+}
+
+func (l *consoleLimiter) writeSummary(t time.Time, suppressed int) {
+	ent := zapcore.Entry{Level: zap.WarnLevel, Time: t, Message: "console output suppressed"}
+	for _, core := range l.cores {
+		// Checked so that with SplitStreams only the stream taking Warn reports
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(zap.Int("console_suppressed", suppressed))
+		}
+	}
+}
+
+// rateLimitCore drops console lines above the configured rate. The number of
+// suppressed lines is reported with the first line of the next second, within
+// a second by the limiter's ticker otherwise, and on Sync.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *consoleLimiter
+}
+
+func newRateLimitCore(core zapcore.Core, limiter *consoleLimiter) *rateLimitCore {
+	limiter.cores = append(limiter.cores, core)
+	return &rateLimitCore{Core: core, limiter: limiter}
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *rateLimitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ok, suppressed := c.limiter.allow()
+	if suppressed > 0 {
+		c.limiter.writeSummary(clock().Now(), suppressed)
+	}
+	if !ok {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *rateLimitCore) Sync() error {
+	c.limiter.Close()
+	return c.Core.Sync()
+}
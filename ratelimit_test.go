@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// suppressedCounts returns the console_suppressed values of the summaries in logs
+func suppressedCounts(logs *observer.ObservedLogs) []int64 {
+	var counts []int64
+	for _, e := range logs.FilterMessage("console output suppressed").All() {
+		counts = append(counts, e.ContextMap()["console_suppressed"].(int64))
+	}
+	return counts
+}
+
+func TestRateLimitSummaryOnTimer(t *testing.T) {
+	defer Snapshot()()
+	clk := newFakeClock()
+	SetClock(clk)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	limiter := &consoleLimiter{limit: 2}
+	defer limiter.Close()
+	l := zap.New(newRateLimitCore(core, limiter))
+
+	for i := 0; i < 5; i++ {
+		// Entry times do not move the window, the clock does
+		l.Info("burst", zap.Int("i", i))
+	}
+	if n := logs.FilterMessage("burst").Len(); n != 2 {
+		t.Fatalf("wrote %d lines, want the limit of 2", n)
+	}
+	d, tick, ok := clk.Ticker(0)
+	if !ok {
+		t.Fatal("no summary ticker started once lines were suppressed")
+	}
+	if d != time.Second {
+		t.Fatalf("summary ticker interval = %v, want 1s", d)
+	}
+
+	limiter.mu.Lock()
+	done := limiter.done
+	limiter.mu.Unlock()
+
+	// No further lines: the window ends and the summary is still written. The
+	// second tick finds no suppressed lines, so the ticker stops.
+	clk.Add(time.Second)
+	tick <- clk.Now()
+	tick <- clk.Now()
+	<-done
+	if got := suppressedCounts(logs); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("summaries = %v, want one of 3", got)
+	}
+
+	// A new burst starts a new ticker
+	for i := 0; i < 3; i++ {
+		l.Info("burst")
+	}
+	if _, _, ok := clk.Ticker(1); !ok {
+		t.Fatal("no summary ticker started for the second burst")
+	}
+}
+
+func TestRateLimitSummaryOnSync(t *testing.T) {
+	defer Snapshot()()
+	clk := newFakeClock()
+	SetClock(clk)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	limiter := &consoleLimiter{limit: 1}
+	l := zap.New(newRateLimitCore(core, limiter))
+
+	for i := 0; i < 3; i++ {
+		l.Info("burst")
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := suppressedCounts(logs); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("summaries = %v, want one of 2", got)
+	}
+	limiter.mu.Lock()
+	running := limiter.stop != nil
+	limiter.mu.Unlock()
+	if running {
+		t.Fatal("summary ticker still running after Sync")
+	}
+
+	// Nothing left to report
+	_ = l.Sync()
+	if got := suppressedCounts(logs); len(got) != 1 {
+		t.Fatalf("summaries after a second Sync = %v, want still one", got)
+	}
+}
+
+func TestRateLimitSummaryOnlyOnWarnStream(t *testing.T) {
+	defer Snapshot()()
+	SetClock(newFakeClock())
+
+	stdout, outLogs := observer.New(zap.LevelEnablerFunc(func(l zapcore.Level) bool { return l < zapcore.WarnLevel }))
+	stderr, errLogs := observer.New(zapcore.WarnLevel)
+	limiter := &consoleLimiter{limit: 1}
+	l := zap.New(zapcore.NewTee(newRateLimitCore(stdout, limiter), newRateLimitCore(stderr, limiter)))
+
+	l.Info("burst")
+	l.Info("burst")
+	// Synced in order, the stdout core takes the count first
+	_ = l.Sync()
+	if got := suppressedCounts(outLogs); len(got) != 0 {
+		t.Fatalf("stdout summaries = %v, want none", got)
+	}
+	if got := suppressedCounts(errLogs); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("stderr summaries = %v, want one of 1", got)
+	}
+}
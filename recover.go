@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RecoverPolicy controls what SafeGo does after logging a recovered panic
+type RecoverPolicy int32
+
+const (
+	// RecoverSwallow logs the panic and lets the goroutine return (default)
+	RecoverSwallow RecoverPolicy = iota
+	// RecoverRepanic logs the panic and panics again, crashing the process
+	RecoverRepanic
+)
+
+var recoverPolicy atomic.Int32
+
+// SetRecoverPolicy sets how SafeGo and SafeGoCtx handle recovered panics
+func SetRecoverPolicy(p RecoverPolicy) {
+	recoverPolicy.Store(int32(p))
+}
+
+// SafeGo runs fn in a new goroutine and logs any panic at Error level
+func SafeGo(fn func()) {
+	SafeGoCtx(context.Background(), func(context.Context) { fn() })
+}
+
+// SafeGoCtx runs fn in a new goroutine with ctx and logs any panic at Error
+// level together with the context fields
+func SafeGoCtx(ctx context.Context, fn func(ctx context.Context)) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logPanic(ctx, r, debug.Stack())
+				if RecoverPolicy(recoverPolicy.Load()) == RecoverRepanic {
+					panic(r)
+				}
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// Guard runs fn and returns the value of any panic it raised, after logging
+// it with its stack. It returns nil when fn completes normally.
+func Guard(fn func()) (recovered interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(context.Background(), r, debug.Stack())
+			recovered = r
+		}
+	}()
+	fn()
+	return nil
+}
+
+// CapturePanics logs panics of next with the request's context fields, method
+// and path, then panics again so outer middleware and net/http still see them.
+// http.ErrAbortHandler is passed on without logging.
+func CapturePanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p != http.ErrAbortHandler {
+					ctx := ContextWithBaggage(r.Context(), Baggage{Extra: map[string]string{
+						"http_method": r.Method,
+						"http_path":   r.URL.Path,
+					}})
+					logPanic(ctx, p, debug.Stack())
+				}
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// logPanic logs a recovered panic value with the stack captured at recovery
+func logPanic(ctx context.Context, r interface{}, stack []byte) {
+	// The recovery stack replaces the one zap would capture at this call site
+	logger := ctxLogger(ctx).Desugar().WithOptions(zap.AddStacktrace(zapcore.FatalLevel + 1)).Sugar()
+	logger.Errorw("recovered from panic",
+		"panic", fmt.Sprint(r),
+		"stacktrace", string(stack),
+	)
+}
@@ -0,0 +1,28 @@
+package logger
+
+// Retry logs the attempts of a retried operation with consistent fields:
+// op, attempt and error for failed attempts, op and total_attempts on success
+type Retry struct {
+	op string
+}
+
+// RetryLogger returns a Retry for the operation op, e.g. "payments.charge"
+func RetryLogger(op string) *Retry {
+	return &Retry{op: op}
+}
+
+// Attempt logs the failure of attempt n, counting from 1, at Warn
+func (r *Retry) Attempt(n int, err error) {
+	ensureInitialized()
+	kv := []interface{}{"op", r.op, "attempt", n}
+	if err != nil {
+		kv = append(kv, "error", err.Error())
+	}
+	globalLogger.Warnw("attempt failed", kv...)
+}
+
+// Success logs at Info that the operation succeeded after n attempts in total
+func (r *Retry) Success(n int) {
+	ensureInitialized()
+	globalLogger.Infow("operation succeeded", "op", r.op, "total_attempts", n)
+}
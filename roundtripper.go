@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// redactedValue replaces redacted query parameter values
+const redactedValue = "REDACTED"
+
+// LoggingRoundTripper logs a summary of every outbound HTTP request: method,
+// URL, status, duration and body sizes. Successful calls are logged at Debug,
+// 4xx/5xx responses and transport errors at Warn. Context fields of the
+// request's context are attached, and its trace is propagated in a W3C
+// traceparent header unless the request already has one.
+type LoggingRoundTripper struct {
+	Next        http.RoundTripper // Optional: defaults to http.DefaultTransport
+	RedactQuery []string          // Optional: query parameters whose values are replaced, matched case-insensitively, e.g. "api_key"
+}
+
+func (t *LoggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if req.Header.Get(traceparentHeader) == "" {
+		if tp := Traceparent(req.Context()); tp != "" {
+			// A RoundTripper must not modify the caller's request
+			req = req.Clone(req.Context())
+			req.Header.Set(traceparentHeader, tp)
+		}
+	}
+
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+
+	kv := []interface{}{
+		"method", req.Method,
+		"url", t.redactURL(req.URL),
+		"duration", time.Since(start),
+	}
+	if req.ContentLength > 0 {
+		kv = append(kv, "request_bytes", req.ContentLength)
+	}
+
+	// Caller and stack would point inside net/http, which is of no use
+	l := ctxLogger(req.Context()).WithOptions(zap.WithCaller(false), zap.AddStacktrace(zap.FatalLevel+1))
+	if err != nil {
+		l.Warnw("http request failed", append(kv, "error", err.Error())...)
+		return resp, err
+	}
+
+	kv = append(kv, "status", resp.StatusCode)
+	if resp.ContentLength >= 0 {
+		kv = append(kv, "response_bytes", resp.ContentLength)
+	}
+	if resp.StatusCode >= 400 {
+		l.Warnw("http request", kv...)
+	} else {
+		l.Debugw("http request", kv...)
+	}
+	return resp, nil
+}
+
+// redactURL returns u without user info and with the configured query values replaced
+func (t *LoggingRoundTripper) redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := *u
+	redacted.User = nil
+	if len(t.RedactQuery) == 0 || redacted.RawQuery == "" {
+		return redacted.String()
+	}
+
+	query := redacted.Query()
+	for key, values := range query {
+		for _, name := range t.RedactQuery {
+			if strings.EqualFold(key, name) {
+				for i := range values {
+					values[i] = redactedValue
+				}
+			}
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// minRuntimeStatsInterval bounds how often StartRuntimeStats reads the memory
+// stats, runtime.ReadMemStats stops the world
+const minRuntimeStatsInterval = time.Second
+
+// LogRuntimeStats logs the goroutine count and memory stats at Info:
+// goroutines, heap_alloc, heap_objects, num_gc, gc_pause_total_ms and
+// gc_pause_last_ms. It calls runtime.ReadMemStats, which briefly stops the
+// world, so avoid calling it in hot paths.
+func LogRuntimeStats() {
+	ensureInitialized()
+	globalLogger.Infow("runtime stats", runtimeStatsFields()...)
+}
+
+// StartRuntimeStats logs the runtime stats every interval, at least a second,
+// until the returned function is called
+func StartRuntimeStats(interval time.Duration) (stop func()) {
+	ensureInitialized()
+	if interval < minRuntimeStatsInterval {
+		interval = minRuntimeStatsInterval
+	}
+
+	done := make(chan struct{})
+	go func() {
+		// Caller would point at this goroutine
+		l := globalLogger.WithOptions(zap.WithCaller(false))
+		ticker := clock().NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.Infow("runtime stats", runtimeStatsFields()...)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+func runtimeStatsFields() []interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	var lastPause uint64
+	if m.NumGC > 0 {
+		lastPause = m.PauseNs[(m.NumGC+255)%256]
+	}
+	return []interface{}{
+		"goroutines", runtime.NumGoroutine(),
+		"heap_alloc", m.HeapAlloc,
+		"heap_objects", m.HeapObjects,
+		"num_gc", m.NumGC,
+		"gc_pause_total_ms", float64(m.PauseTotalNs) / float64(time.Millisecond),
+		"gc_pause_last_ms", float64(lastPause) / float64(time.Millisecond),
+	}
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type sampledKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// samplingStats counts the entries the sampler drops per level and message
+// and reports them periodically, bypassing sampling
+type samplingStats struct {
+	core zapcore.Core // unsampled, with the initial fields
+
+	mu      sync.Mutex
+	dropped map[sampledKey]int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newSamplingStats(core zapcore.Core, interval time.Duration) *samplingStats {
+	s := &samplingStats{
+		core:    core,
+		dropped: map[sampledKey]int{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run(interval)
+	return s
+}
+
+// hook is the sampler hook recording dropped entries
+func (s *samplingStats) hook(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.dropped[sampledKey{ent.Level, ent.Message}]++
+	s.mu.Unlock()
+}
+
+func (s *samplingStats) run(interval time.Duration) {
+	defer close(s.done)
+	ticker := clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stop:
+			s.flush()
+			return
+		}
+	}
+}
+
+// flush logs one entry per message dropped since the last flush, at the level
+// of the dropped entries
+func (s *samplingStats) flush() {
+	s.mu.Lock()
+	dropped := s.dropped
+	s.dropped = map[sampledKey]int{}
+	s.mu.Unlock()
+
+	keys := make([]sampledKey, 0, len(dropped))
+	for k := range dropped {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return keys[i].level < keys[j].level
+		}
+		return keys[i].message < keys[j].message
+	})
+
+	now := clock().Now()
+	for _, k := range keys {
+		ent := zapcore.Entry{Level: k.level, Time: now, Message: "sampled out entries"}
+		if ce := s.core.Check(ent, nil); ce != nil {
+			ce.Write(zap.String("sampled_message", k.message), zap.Int("sampled_dropped", dropped[k]))
+		}
+	}
+}
+
+// Close reports the remaining counts and stops the periodic reports
+func (s *samplingStats) Close() {
+	close(s.stop)
+	<-s.done
+}
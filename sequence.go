@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// seqKey is the field numbering the entries
+const seqKey = "seq"
+
+// seqCore numbers the entries of a pipeline with a strictly increasing seq
+// field, starting at 1 for every process, so consumers can detect gaps. The
+// number is taken when the entry is written, after the level and sampling
+// gates passed and repeats were collapsed, and shared by all sinks of the entry.
+type seqCore struct {
+	zapcore.Core
+	seq *atomic.Uint64
+}
+
+// newSeqCore numbers entries from seq, nil disables numbering. The logger and
+// the audit logger share one counter since they write to the same sinks.
+func newSeqCore(core zapcore.Core, seq *atomic.Uint64) zapcore.Core {
+	if seq == nil {
+		return core
+	}
+	return &seqCore{Core: core, seq: seq}
+}
+
+func (c *seqCore) With(fields []zapcore.Field) zapcore.Core {
+	return &seqCore{Core: c.Core.With(fields), seq: c.seq}
+}
+
+func (c *seqCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return deferredWrite(c.Core, ent, ce, c.number)
+}
+
+// number adds the next seq to an entry at write time
+func (c *seqCore) number(_ zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+	return append(fields[:len(fields):len(fields)], zap.Uint64(seqKey, c.seq.Add(1))), true
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// serviceLogger builds a global logger with another service field, and
+// without the session trace ID if asked, nil when the global logger was not
+// built by Init
+var serviceLogger func(name string, withoutTrace bool) *zap.Logger
+
+// WithService returns a logger whose entries carry service=name instead of
+// the configured ServiceName, for code logging on behalf of another logical
+// service such as an embedded library. The service field is replaced, not
+// added a second time; other initial fields and default fields are kept.
+// When the global logger was installed with Use, or is the fallback logger,
+// the service field is added to the existing fields instead.
+func WithService(name string) *zap.SugaredLogger {
+	ensureInitialized()
+	// Returned for direct use, so drop the skip meant for the package functions
+	return serviceSugar(name, false).WithOptions(zap.AddCallerSkip(-1))
+}
+
+// WithServiceCtx is WithService with the context fields of ctx attached
+func WithServiceCtx(ctx context.Context, name string) *zap.SugaredLogger {
+	ensureInitialized()
+	// A trace ID from ctx replaces the session one
+	l := serviceSugar(name, BaggageFromContext(ctx).TraceID != "").WithOptions(zap.AddCallerSkip(-1))
+	if kv := ctxLoggerFields(ctx); len(kv) > 0 {
+		l = l.With(kv...)
+	}
+	return l
+}
+
+func serviceSugar(name string, withoutTrace bool) *zap.SugaredLogger {
+	if serviceLogger == nil {
+		return globalLogger.With("service", name)
+	}
+	return serviceLogger(name, withoutTrace).With(defaultFields...).Sugar()
+}
+
+// removeField returns fields without the field of this key
+func removeField(fields []zap.Field, key string) []zap.Field {
+	out := make([]zap.Field, 0, len(fields))
+	for _, existing := range fields {
+		if existing.Key != key {
+			out = append(out, existing)
+		}
+	}
+	return out
+}
+
+// replaceField returns fields with the field of the same key replaced by f
+func replaceField(fields []zap.Field, f zap.Field) []zap.Field {
+	out := make([]zap.Field, 0, len(fields))
+	for _, existing := range fields {
+		if existing.Key == f.Key {
+			existing = f
+		}
+		out = append(out, existing)
+	}
+	return out
+}
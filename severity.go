@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// LogAtSeverity logs at the level mapped from a syslog severity, for bridging
+// from systems that speak severity numbers. The severity is logged as
+// syslog_severity.
+//
+//	0 emerg, 1 alert, 2 crit, 3 err  Error
+//	4 warning                        Warn
+//	5 notice, 6 info                 Info
+//	7 debug                          Debug
+//
+// Severities below 0 map to Error and above 7 to Debug. Emergencies are not
+// logged at Fatal since that would exit the process.
+func LogAtSeverity(sev int, msg string, keysAndValues ...interface{}) {
+	ensureInitialized()
+
+	kv := make([]interface{}, 0, 2+len(keysAndValues))
+	kv = append(kv, "syslog_severity", sev)
+	globalLogger.Logw(severityLevel(sev), msg, append(kv, keysAndValues...)...)
+}
+
+func severityLevel(sev int) zapcore.Level {
+	switch {
+	case sev <= 3:
+		return zapcore.ErrorLevel
+	case sev == 4:
+		return zapcore.WarnLevel
+	case sev <= 6:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
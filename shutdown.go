@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownFlushTimeout bounds how long a shutdown signal waits for the flush
+const shutdownFlushTimeout = 5 * time.Second
+
+// InstallShutdownFlush flushes and closes the global logger when one of the
+// signals arrives, SIGTERM and SIGINT by default, then restores the signal's
+// default behavior and raises it again so the process terminates as it would
+// have. The flush is abandoned after 5 seconds.
+//
+// It is opt-in and uses its own signal.Notify channel, so it coexists with
+// other handlers such as one reopening log files on SIGHUP, as long as that
+// signal isn't passed here. Applications that handle SIGTERM themselves
+// should call Close in their own shutdown path instead.
+func InstallShutdownFlush(signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	go func() {
+		sig := <-ch
+		signal.Stop(ch)
+
+		done := make(chan struct{})
+		go func() {
+			_ = Close()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(shutdownFlushTimeout):
+		}
+
+		signal.Reset(sig)
+		if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+			// Give the default handler a moment to terminate the process
+			time.Sleep(time.Second)
+		}
+		// Raising is not supported everywhere, e.g. on Windows
+		os.Exit(1)
+	}()
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Snapshot captures the global logger state and returns a function restoring
+// it, meant for tests that call Init, SetLevel, SetNamedLevel or register hooks:
+//
+//	defer logger.Snapshot()()
+//
+// It covers the global and audit loggers, configuration, levels including
+// field level overrides, registered hooks, filters, writers, context fields,
+// package tags, field encoders, struct default fields, the trace ID generator
+// and session trace ID, the clock, the recover policy, the fallback config,
+// expected errors, registered level names and the HealthCheck error count.
+// Loggers built by Init after the snapshot are not closed by the restore.
+// Not intended for production use.
+func Snapshot() func() {
+	registryMu.RLock()
+	savedHooks, savedFilters, savedWriters := hooks, filters, writers
+	registryMu.RUnlock()
+
+	namedMu.Lock()
+	savedNamed := make(map[string]zapcore.Level, len(namedLevels))
+	for name, level := range namedLevels {
+		savedNamed[name] = level.Level()
+	}
+	namedMu.Unlock()
+
+	contextFieldsMu.RLock()
+	savedCtxFields := registeredCtx
+	contextFieldsMu.RUnlock()
+
+	traceIDMu.RLock()
+	savedTraceID := traceIDGenerator
+	traceIDMu.RUnlock()
+
+	expectedMu.RLock()
+	savedExpected := expectedErrors
+	expectedMu.RUnlock()
+
+	levelNamesMu.RLock()
+	savedLevelNames := make(map[zapcore.Level]string, len(levelNames))
+	for level, name := range levelNames {
+		savedLevelNames[level] = name
+	}
+	levelNamesMu.RUnlock()
+
+	var (
+		savedLogger      = globalLogger
+		savedAudit       = globalAudit
+		savedCritical    = globalCritical
+		savedTraceless   = globalTraceless
+		savedInternal    = internal
+		savedConfig      = globalConfig
+		savedStats       = globalStats
+		savedSinks       = globalSinks
+		savedAccess      = globalAccess
+		savedCleanup     = globalCleanup
+		savedInitialized = initialized
+		savedLevel       = globalLevel
+		savedLevelValue  = globalLevel.Level()
+		savedPolicy      = recoverPolicy.Load()
+		savedTags        = currentTags.Load()
+		savedFieldLevels = activeFields.Load()
+		savedEncoders    = activeFieldEncoders.Load()
+		savedDisabled    = loggingDisabled.Load()
+		savedDefaults    = defaultFields
+		savedClock       = currentClock.Load()
+		savedPreInit     = preInit
+		savedService     = serviceLogger
+		savedPipeline    = globalPipeline
+		savedUsed        = usedLogger
+		savedSessionID   = sessionTraceID
+		savedFallback    = fallbackConfig
+		savedHealth      = healthErrors.Load()
+	)
+
+	return func() {
+		registryMu.Lock()
+		hooks, filters, writers = savedHooks, savedFilters, savedWriters
+		registryMu.Unlock()
+
+		namedMu.Lock()
+		namedLevels = make(map[string]zap.AtomicLevel, len(savedNamed))
+		for name, level := range savedNamed {
+			namedLevels[name] = zap.NewAtomicLevelAt(level)
+		}
+		updateNamedMin()
+		namedMu.Unlock()
+
+		contextFieldsMu.Lock()
+		registeredCtx = savedCtxFields
+		contextFieldsMu.Unlock()
+
+		traceIDMu.Lock()
+		traceIDGenerator = savedTraceID
+		traceIDMu.Unlock()
+
+		expectedMu.Lock()
+		expectedErrors = savedExpected
+		expectedMu.Unlock()
+
+		levelNamesMu.Lock()
+		levelNames = make(map[zapcore.Level]string, len(savedLevelNames))
+		for level, name := range savedLevelNames {
+			levelNames[level] = name
+		}
+		levelNamesMu.Unlock()
+
+		globalLogger = savedLogger
+		globalAudit = savedAudit
+		globalCritical = savedCritical
+		globalTraceless = savedTraceless
+		internal = savedInternal
+		globalConfig = savedConfig
+		globalStats = savedStats
+		globalSinks = savedSinks
+		globalAccess = savedAccess
+		globalCleanup = savedCleanup
+		initialized = savedInitialized
+		globalLevel = savedLevel
+		globalLevel.SetLevel(savedLevelValue)
+		recoverPolicy.Store(savedPolicy)
+		currentTags.Store(savedTags)
+		activeFields.Store(savedFieldLevels)
+		activeFieldEncoders.Store(savedEncoders)
+		loggingDisabled.Store(savedDisabled)
+		defaultFields = savedDefaults
+		currentClock.Store(savedClock)
+		preInit = savedPreInit
+		serviceLogger = savedService
+		globalPipeline = savedPipeline
+		usedLogger = savedUsed
+		sessionTraceID = savedSessionID
+		fallbackConfig = savedFallback
+		healthErrors.Store(savedHealth)
+	}
+}
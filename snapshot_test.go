@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSnapshotRestoresRegistrations(t *testing.T) {
+	errNotFound := errors.New("not found")
+	SetExpectedErrors(errNotFound)
+	defer SetExpectedErrors()
+
+	restore := Snapshot()
+	SetFallbackConfig(zap.NewProductionConfig())
+	SetExpectedErrors()
+	RegisterLevelName(zapcore.WarnLevel, "warning")
+	RegisterLevelName(TraceLevel-1, "verbose")
+	healthErrors.Store(42)
+	restore()
+
+	if fallbackConfig != nil {
+		t.Error("fallback config kept after restore")
+	}
+	if !isExpected(errNotFound) {
+		t.Error("expected errors not restored")
+	}
+	if name, ok := registeredLevelName(zapcore.WarnLevel); ok {
+		t.Errorf("warn level name %q kept after restore", name)
+	}
+	if _, ok := registeredLevelName(TraceLevel - 1); ok {
+		t.Error("custom level name kept after restore")
+	}
+	if name, _ := registeredLevelName(TraceLevel); name != "trace" {
+		t.Errorf("trace level name = %q after restore, want \"trace\"", name)
+	}
+	if n := healthErrors.Load(); n != 0 {
+		t.Errorf("HealthCheck error count = %d after restore, want 0", n)
+	}
+}
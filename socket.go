@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Unix socket sink limits
+const (
+	socketBufferBytes  = 1 << 20 // entries held while disconnected
+	socketDialTimeout  = time.Second
+	socketWriteTimeout = time.Second // a write blocked longer counts as a disconnect
+	socketMinBackoff   = 100 * time.Millisecond
+	socketMaxBackoff   = 30 * time.Second
+)
+
+// socketSyncer writes to a Unix domain socket, reconnecting with exponential
+// backoff. While disconnected, writes are buffered up to socketBufferBytes and
+// sent once the connection is back; writes beyond that are dropped from the
+// socket stream, the file sink still has them. The first write dials, so a
+// shipper that starts after the service is not an error. A shipper that stops
+// reading is treated as gone once a write blocks for socketWriteTimeout, and
+// only dialed again after socketMaxBackoff.
+type socketSyncer struct {
+	path    string
+	timeout time.Duration // write timeout, socketWriteTimeout when zero
+
+	mu       sync.Mutex
+	conn     net.Conn
+	pending  [][]byte
+	size     int // bytes in pending
+	dropped  int // writes dropped since the connection was lost
+	backoff  time.Duration
+	nextDial time.Time
+	down     bool // disconnect was reported
+}
+
+func (s *socketSyncer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil && !clock().Now().Before(s.nextDial) {
+		s.connectLocked()
+	}
+	if s.conn != nil && s.flushLocked() {
+		err := s.writeLocked(p)
+		if err == nil {
+			return len(p), nil
+		}
+		s.disconnectLocked(err)
+	}
+
+	if s.size+len(p) > socketBufferBytes {
+		s.dropped++
+		return len(p), nil
+	}
+	// The caller may reuse p once Write returns
+	s.pending = append(s.pending, append([]byte(nil), p...))
+	s.size += len(p)
+	return len(p), nil
+}
+
+func (s *socketSyncer) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.flushLocked()
+	}
+	return nil
+}
+
+func (s *socketSyncer) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.flushLocked()
+	}
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// connectLocked dials the socket and schedules the next attempt on failure
+func (s *socketSyncer) connectLocked() {
+	conn, err := net.DialTimeout("unix", s.path, socketDialTimeout)
+	if err != nil {
+		if s.backoff == 0 {
+			s.backoff = socketMinBackoff
+		} else if s.backoff < socketMaxBackoff {
+			s.backoff = min(2*s.backoff, socketMaxBackoff)
+		}
+		s.nextDial = clock().Now().Add(s.backoff)
+		s.reportLocked(false, err)
+		return
+	}
+	s.conn, s.backoff = conn, 0
+	s.reportLocked(true, nil)
+}
+
+// flushLocked sends the buffered writes and reports whether all were sent
+func (s *socketSyncer) flushLocked() bool {
+	for len(s.pending) > 0 {
+		if err := s.writeLocked(s.pending[0]); err != nil {
+			s.disconnectLocked(err)
+			return false
+		}
+		s.size -= len(s.pending[0])
+		s.pending = s.pending[1:]
+	}
+	s.pending = nil
+	return true
+}
+
+// writeLocked writes p with a deadline, so a stalled shipper does not block
+// every goroutine that logs. A partly written entry is sent again in full
+// after reconnecting.
+func (s *socketSyncer) writeLocked(p []byte) error {
+	timeout := s.timeout
+	if timeout <= 0 {
+		timeout = socketWriteTimeout
+	}
+	// Deadlines are in wall time, unlike the package clock
+	if err := s.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(p)
+	return err
+}
+
+func (s *socketSyncer) disconnectLocked(err error) {
+	_ = s.conn.Close()
+	s.conn = nil
+	s.backoff = socketMinBackoff
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		// Still accepting connections but not reading, redialing soon would
+		// stall the next write as well
+		s.backoff = socketMaxBackoff
+	}
+	s.nextDial = clock().Now().Add(s.backoff)
+	s.reportLocked(false, err)
+}
+
+// reportLocked logs when the socket goes down or comes back. Logged from
+// another goroutine since the entry is written to this sink as well.
+func (s *socketSyncer) reportLocked(up bool, err error) {
+	if up == !s.down {
+		return
+	}
+	s.down = !up
+	dropped := s.dropped
+	if up {
+		s.dropped = 0
+	}
+	// Called directly rather than through a package function, hence the skip
+	meta := InternalLogger().WithOptions(zap.AddCallerSkip(-1))
+	go func() {
+		if up {
+			meta.Infow("log socket reconnected", "socket_path", s.path, "dropped", dropped)
+			return
+		}
+		kv := []interface{}{"socket_path", s.path}
+		if err != nil {
+			kv = append(kv, "error", err.Error())
+		}
+		meta.Warnw("log socket unavailable, buffering entries", kv...)
+	}()
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSocketStalledShipperTimesOut(t *testing.T) {
+	defer Snapshot()()
+	// Receives the disconnect report
+	core, _ := observer.New(zapcore.DebugLevel)
+	Use(zap.New(core))
+	path := filepath.Join(t.TempDir(), "ship.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// Accepts but never reads
+	var mu sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	s := &socketSyncer{path: path, timeout: 50 * time.Millisecond}
+	defer s.Close()
+
+	line := bytes.Repeat([]byte("x"), 64<<10)
+	start := time.Now()
+	for i := 0; i < 200; i++ {
+		if _, err := s.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("writes to a stalled shipper took %v", elapsed)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		t.Fatal("still connected to a shipper that stopped reading")
+	}
+	if s.pending == nil && s.dropped == 0 {
+		t.Fatal("entries after the stall were neither buffered nor dropped")
+	}
+	if wait := s.nextDial.Sub(clock().Now()); wait < socketMaxBackoff-time.Second {
+		t.Fatalf("next dial in %v, want about %v after a stall", wait, socketMaxBackoff)
+	}
+}
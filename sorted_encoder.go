@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"sort"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// sortedNode is a recorded field, or a namespace holding the fields added after it
+type sortedNode struct {
+	key      string
+	add      func(zapcore.ObjectEncoder) // nil for namespaces
+	children []*sortedNode
+}
+
+func (n *sortedNode) clone() *sortedNode {
+	c := &sortedNode{key: n.key, add: n.add}
+	if len(n.children) > 0 {
+		c.children = make([]*sortedNode, len(n.children))
+		for i, child := range n.children {
+			c.children[i] = child.clone()
+		}
+	}
+	return c
+}
+
+// sortedJSONEncoder records fields instead of encoding them immediately and
+// replays them in alphabetical key order into a plain JSON encoder. The
+// standard entry fields (timestamp, level, message, ...) are written by the
+// JSON encoder itself and therefore always come first.
+type sortedJSONEncoder struct {
+	base zapcore.Encoder // JSON encoder without any context fields
+	root *sortedNode
+	path []*sortedNode // open namespaces, innermost last
+}
+
+func newSortedJSONEncoder(cfg zapcore.EncoderConfig) *sortedJSONEncoder {
+	return &sortedJSONEncoder{
+		base: zapcore.NewJSONEncoder(cfg),
+		root: &sortedNode{},
+	}
+}
+
+func (e *sortedJSONEncoder) current() *sortedNode {
+	if len(e.path) > 0 {
+		return e.path[len(e.path)-1]
+	}
+	return e.root
+}
+
+func (e *sortedJSONEncoder) record(key string, add func(zapcore.ObjectEncoder)) {
+	n := e.current()
+	n.children = append(n.children, &sortedNode{key: key, add: add})
+}
+
+// Clone copies the recorded fields. An open namespace is always the last child
+// of its parent, so the namespace path can be rebuilt by following last children.
+func (e *sortedJSONEncoder) Clone() zapcore.Encoder {
+	c := &sortedJSONEncoder{base: e.base, root: e.root.clone()}
+	n := c.root
+	for range e.path {
+		n = n.children[len(n.children)-1]
+		c.path = append(c.path, n)
+	}
+	return c
+}
+
+func (e *sortedJSONEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(*sortedJSONEncoder)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+
+	enc := e.base.Clone()
+	replaySorted(enc, final.root)
+	return enc.EncodeEntry(ent, nil)
+}
+
+// replaySorted adds the node's fields to enc in key order. A namespace swallows
+// everything added after it, so it is replayed last.
+func replaySorted(enc zapcore.ObjectEncoder, n *sortedNode) {
+	var namespace *sortedNode
+	plain := make([]*sortedNode, 0, len(n.children))
+	for _, child := range n.children {
+		if child.add == nil {
+			namespace = child
+			continue
+		}
+		plain = append(plain, child)
+	}
+	sort.SliceStable(plain, func(i, j int) bool { return plain[i].key < plain[j].key })
+
+	for _, child := range plain {
+		child.add(enc)
+	}
+	if namespace != nil {
+		enc.OpenNamespace(namespace.key)
+		replaySorted(enc, namespace)
+	}
+}
+
+func (e *sortedJSONEncoder) OpenNamespace(key string) {
+	ns := &sortedNode{key: key}
+	n := e.current()
+	n.children = append(n.children, ns)
+	e.path = append(e.path, ns)
+}
+
+func (e *sortedJSONEncoder) AddArray(key string, v zapcore.ArrayMarshaler) error {
+	e.record(key, func(enc zapcore.ObjectEncoder) {
+		if err := enc.AddArray(key, v); err != nil {
+			enc.AddString(key+"Error", err.Error())
+		}
+	})
+	return nil
+}
+
+func (e *sortedJSONEncoder) AddObject(key string, v zapcore.ObjectMarshaler) error {
+	e.record(key, func(enc zapcore.ObjectEncoder) {
+		if err := enc.AddObject(key, v); err != nil {
+			enc.AddString(key+"Error", err.Error())
+		}
+	})
+	return nil
+}
+
+func (e *sortedJSONEncoder) AddReflected(key string, v interface{}) error {
+	e.record(key, func(enc zapcore.ObjectEncoder) {
+		if err := enc.AddReflected(key, v); err != nil {
+			enc.AddString(key+"Error", err.Error())
+		}
+	})
+	return nil
+}
+
+func (e *sortedJSONEncoder) AddBinary(key string, v []byte) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddBinary(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddByteString(key string, v []byte) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddByteString(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddBool(key string, v bool) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddBool(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddComplex128(key string, v complex128) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddComplex128(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddComplex64(key string, v complex64) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddComplex64(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddDuration(key string, v time.Duration) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddDuration(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddFloat64(key string, v float64) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddFloat64(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddFloat32(key string, v float32) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddFloat32(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddInt(key string, v int) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddInt(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddInt64(key string, v int64) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddInt64(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddInt32(key string, v int32) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddInt32(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddInt16(key string, v int16) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddInt16(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddInt8(key string, v int8) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddInt8(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddString(key string, v string) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddString(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddTime(key string, v time.Time) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddTime(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddUint(key string, v uint) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddUint(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddUint64(key string, v uint64) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddUint64(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddUint32(key string, v uint32) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddUint32(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddUint16(key string, v uint16) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddUint16(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddUint8(key string, v uint8) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddUint8(key, v) })
+}
+
+func (e *sortedJSONEncoder) AddUintptr(key string, v uintptr) {
+	e.record(key, func(enc zapcore.ObjectEncoder) { enc.AddUintptr(key, v) })
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StartSpan starts a lightweight span named name. The returned context carries
+// a new span_id with the span_id of ctx, if any, as parent_span_id, so the Ctx
+// logging functions can be used to rebuild call trees. The span start and end
+// are logged at Debug, the end with the duration; call the returned function
+// when the operation finishes. When Debug is disabled only the IDs are created.
+func StartSpan(ctx context.Context, name string) (context.Context, func()) {
+	ensureInitialized()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent := BaggageFromContext(ctx).SpanID
+	ctx = ContextWithBaggage(ctx, Baggage{SpanID: newSpanID(), ParentSpanID: parent})
+
+	if !globalLogger.Desugar().Core().Enabled(zap.DebugLevel) {
+		return ctx, func() {}
+	}
+
+	start := time.Now()
+	logSpan(ctx, "span started", "span", name)
+	return ctx, func() {
+		logSpan(ctx, "span finished", "span", name, "duration", time.Since(start))
+	}
+}
+
+// logSpan logs at Debug with the caller of StartSpan or of its end function
+func logSpan(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	ctxLogger(ctx).WithOptions(zap.AddCallerSkip(1)).Debugw(msg, keysAndValues...)
+}
+
+// newSpanID returns a random 64-bit span ID in hex, as used by W3C and OTel
+func newSpanID() string {
+	return fmt.Sprintf("%016x", rand.Uint64())
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// SpanEvent logs msg at Info and, when ctx carries a recording OpenTelemetry
+// span, also adds it to the span as an event with the key-value pairs as
+// attributes, so it shows on the trace timeline. Without a span it is a plain
+// InfoCtx.
+func SpanEvent(ctx context.Context, name string, keysAndValues ...interface{}) {
+	if ctx != nil {
+		if span := trace.SpanFromContext(ctx); span.IsRecording() {
+			span.AddEvent(name, trace.WithAttributes(spanAttributes(keysAndValues)...))
+		}
+	}
+	ctxLogger(ctx).Infow(name, keysAndValues...)
+}
+
+// spanAttributes converts key-value pairs to attributes by encoding them like
+// the logger would
+func spanAttributes(keysAndValues []interface{}) []attribute.KeyValue {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range toFields(keysAndValues) {
+		f.AddTo(enc)
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(enc.Fields))
+	for _, k := range sortedKeys(enc.Fields) {
+		attrs = append(attrs, spanAttribute(k, enc.Fields[k]))
+	}
+	return attrs
+}
+
+func spanAttribute(key string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int8:
+		return attribute.Int64(key, int64(v))
+	case int16:
+		return attribute.Int64(key, int64(v))
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint8:
+		return attribute.Int64(key, int64(v))
+	case uint16:
+		return attribute.Int64(key, int64(v))
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	}
+	// Objects, arrays and uint64 values that may not fit into an int64
+	if b, err := json.Marshal(v); err == nil {
+		return attribute.String(key, string(b))
+	}
+	return attribute.String(key, fmt.Sprint(v))
+}
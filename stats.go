@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// writeStats tracks whether a logger's sinks are accepting writes
+type writeStats struct {
+	lastWrite atomic.Int64 // unix nanoseconds of the last successful write
+	errors    atomic.Uint64
+	dropped   atomic.Uint64 // entries shed under memory pressure
+	degraded  atomic.Bool   // LogFile is stalled and the fallback file is used
+}
+
+func (s *writeStats) record(err error) {
+	if err != nil {
+		s.errors.Add(1)
+		return
+	}
+	s.lastWrite.Store(clock().Now().UnixNano())
+}
+
+// statsCore wraps a sink core and records the outcome of every write
+type statsCore struct {
+	zapcore.Core
+	stats *writeStats
+}
+
+func (c *statsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &statsCore{Core: c.Core.With(fields), stats: c.stats}
+}
+
+func (c *statsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *statsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	c.stats.record(err)
+	return err
+}
+
+// LastWriteTime returns when the global logger last wrote an entry successfully.
+// It returns the zero time if nothing has been written yet.
+func LastWriteTime() time.Time {
+	if globalStats == nil {
+		return time.Time{}
+	}
+	nanos := globalStats.lastWrite.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// WriteErrorCount returns how many writes of the global logger failed, e.g. on a full disk
+func WriteErrorCount() uint64 {
+	if globalStats == nil {
+		return 0
+	}
+	return globalStats.errors.Load()
+}
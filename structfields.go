@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// defaultFields are added to the global loggers by every Init
+var defaultFields []zap.Field
+
+// SetDefaultFieldsFromStruct adds the fields of the struct v, or of the
+// struct v points to, to every entry of the global and audit loggers,
+// including those built by later Init calls:
+//
+//	type deployment struct {
+//		Region string `log:"region"`
+//		Build  struct {
+//			Commit string `log:"commit"`
+//		} `log:"build"`
+//		Token string `log:"-"`
+//	}
+//
+// logs region and build.commit. Untagged exported fields use their Go name,
+// nested structs are flattened with dotted keys, embedded structs without a
+// tag are flattened into the parent and fields tagged "-" are skipped.
+// Structs with their own text, JSON or String form such as time.Time are
+// logged as values. Keys listed in Config.RedactKeys are logged as REDACTED.
+// Calling it again adds further fields.
+func SetDefaultFieldsFromStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("default fields require a struct, got %T", v)
+	}
+
+	ensureInitialized()
+	var fields []zap.Field
+	structFields(rv, "", func(key string, value interface{}) {
+		if redactedKey(key, globalConfig.RedactKeys) {
+			value = redactedValue
+		}
+		fields = append(fields, zap.Any(key, value))
+	})
+
+	defaultFields = append(defaultFields[:len(defaultFields):len(defaultFields)], fields...)
+	globalLogger = globalLogger.With(toInterfaces(fields)...)
+	globalAudit = globalAudit.With(toInterfaces(fields)...)
+	globalCritical = globalCritical.With(toInterfaces(fields)...)
+	if globalTraceless != nil {
+		globalTraceless = globalTraceless.With(toInterfaces(fields)...)
+	}
+	if internal != nil {
+		internal = internal.With(toInterfaces(fields)...)
+	}
+	return nil
+}
+
+// structFields calls add for every logged field of the struct rv
+func structFields(rv reflect.Value, prefix string, add func(key string, value interface{})) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("log")
+		if tag == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		nested := fv.Kind() == reflect.Struct && !hasOwnFormat(fv)
+		if sf.Anonymous && tag == "" && nested {
+			structFields(fv, prefix, add)
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = sf.Name
+		}
+		if nested {
+			structFields(fv, prefix+name+".", add)
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			add(prefix+name, nil)
+			continue
+		}
+		add(prefix+name, fv.Interface())
+	}
+}
+
+// hasOwnFormat reports whether a struct value encodes itself
+func hasOwnFormat(v reflect.Value) bool {
+	switch v.Interface().(type) {
+	case encoding.TextMarshaler, json.Marshaler, fmt.Stringer:
+		return true
+	}
+	if v.CanAddr() {
+		switch v.Addr().Interface().(type) {
+		case encoding.TextMarshaler, json.Marshaler, fmt.Stringer:
+			return true
+		}
+	}
+	return false
+}
+
+// redactedKey reports whether the dotted key or its last part is listed in keys
+func redactedKey(key string, keys []string) bool {
+	leaf := key[strings.LastIndexByte(key, '.')+1:]
+	for _, k := range keys {
+		if strings.EqualFold(k, key) || strings.EqualFold(k, leaf) {
+			return true
+		}
+	}
+	return false
+}
+
+func toInterfaces(fields []zap.Field) []interface{} {
+	out := make([]interface{}, len(fields))
+	for i, f := range fields {
+		out[i] = f
+	}
+	return out
+}
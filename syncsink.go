@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the built-in sinks for SyncSink
+const (
+	SinkFile   = "file"   // LogFile, or all GlogStyle files
+	SinkAudit  = "audit"  // AuditLogFile
+	SinkStdout = "stdout" // console output
+	SinkStderr = "stderr" // console Warn+ output with SplitStreams
+	SinkExtra  = "extra"  // all Config.ExtraCores
+	SinkSocket = "socket" // SocketPath
+	SinkAccess = "access" // AccessLogFile
+)
+
+// syncer is a sink or core that can be flushed
+type syncer interface {
+	Sync() error
+}
+
+// globalSinks are the sinks of the global logger, replaced on every Init
+var globalSinks map[string][]syncer
+
+// SyncSink flushes only the named sink, e.g. the durable file before a slow
+// network core during shutdown. Names are the Sink constants for the built-in
+// sinks, present when configured, or the name a writer was registered with
+// via AddWriter. Unknown names return an error listing the available ones.
+func SyncSink(name string) error {
+	ensureInitialized()
+
+	if sinks, ok := globalSinks[name]; ok {
+		for _, s := range sinks {
+			if err := s.Sync(); err != nil {
+				return fmt.Errorf("failed to sync sink %q: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	registryMu.RLock()
+	registered := writers
+	registryMu.RUnlock()
+	found := false
+	for _, w := range registered {
+		if w.Name != name {
+			continue
+		}
+		found = true
+		if err := w.ws.Sync(); err != nil {
+			return fmt.Errorf("failed to sync writer %q: %w", name, err)
+		}
+	}
+	if found {
+		return nil
+	}
+
+	names := make([]string, 0, len(globalSinks)+len(registered))
+	for n := range globalSinks {
+		names = append(names, n)
+	}
+	for _, w := range registered {
+		names = append(names, w.Name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown sink %q, available: %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// componentKey is the field set by TagByPackage
+const componentKey = "component"
+
+// packageTags is an immutable set of prefix mappings with its resolution cache
+type packageTags struct {
+	prefixes []string // longest first, so the most specific prefix wins
+	tags     map[string]string
+	byPC     sync.Map // uintptr -> string, "" when no prefix matches
+}
+
+var currentTags atomic.Pointer[packageTags]
+
+// TagByPackage tags entries of the global logger with component=value when
+// the calling package's import path equals a prefix or lies below it, e.g.
+// "github.com/acme/app/worker" tags github.com/acme/app/worker/queue too. The
+// longest matching prefix wins. Passing nil or an empty map removes the tags.
+func TagByPackage(prefixes map[string]string) {
+	if len(prefixes) == 0 {
+		currentTags.Store(nil)
+		return
+	}
+
+	t := &packageTags{tags: make(map[string]string, len(prefixes))}
+	for prefix, tag := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		t.prefixes = append(t.prefixes, prefix)
+		t.tags[prefix] = tag
+	}
+	sort.Slice(t.prefixes, func(i, j int) bool { return len(t.prefixes[i]) > len(t.prefixes[j]) })
+	currentTags.Store(t)
+}
+
+// resolve returns the tag for a caller, cached by program counter
+func (t *packageTags) resolve(caller zapcore.EntryCaller) string {
+	if v, ok := t.byPC.Load(caller.PC); ok {
+		return v.(string)
+	}
+
+	pkg := packagePath(caller.Function)
+	tag := ""
+	for _, prefix := range t.prefixes {
+		if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+			tag = t.tags[prefix]
+			break
+		}
+	}
+	t.byPC.Store(caller.PC, tag)
+	return tag
+}
+
+// packagePath extracts the import path from a function name such as
+// "github.com/acme/app/worker.(*Pool).Run"
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// tagCore adds the component field. The caller is only known once zap has
+// checked the entry, so like filterCore it decides at write time.
+type tagCore struct {
+	zapcore.Core
+}
+
+func (c *tagCore) With(fields []zapcore.Field) zapcore.Core {
+	return &tagCore{Core: c.Core.With(fields)}
+}
+
+func (c *tagCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	tags := currentTags.Load()
+	if tags == nil {
+		return c.Core.Check(ent, ce)
+	}
+
+	// The caller is only known at write time
+	return deferredWrite(c.Core, ent, ce, func(ent zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+		if ent.Caller.Defined {
+			if tag := tags.resolve(ent.Caller); tag != "" {
+				fields = append(fields[:len(fields):len(fields)], zap.String(componentKey, tag))
+			}
+		}
+		return fields, true
+	})
+}
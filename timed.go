@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Timed starts timing a block and returns a function that logs name as the
+// message with the elapsed duration_ms and keysAndValues, at Config.TimedLevel:
+//
+//	defer logger.Timed("db.query", "table", "users")()
+//
+// The caller reported is the function calling the returned function.
+func Timed(name string, keysAndValues ...interface{}) func() {
+	return TimedCtx(context.Background(), name, keysAndValues...)
+}
+
+// TimedCtx is Timed with the context fields of ctx attached
+func TimedCtx(ctx context.Context, name string, keysAndValues ...interface{}) func() {
+	start := clock().Now()
+	return func() {
+		elapsed := clock().Now().Sub(start)
+		kv := make([]interface{}, 0, 2+len(keysAndValues))
+		kv = append(kv, "duration_ms", float64(elapsed)/float64(time.Millisecond))
+		ctxLogger(ctx).Logw(timedLevel(), name, append(kv, keysAndValues...)...)
+	}
+}
+
+func timedLevel() zapcore.Level {
+	level, err := zapcore.ParseLevel(globalConfig.TimedLevel)
+	if err != nil || globalConfig.TimedLevel == "" {
+		return zapcore.DebugLevel
+	}
+	return level
+}
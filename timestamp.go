@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logAt writes an entry whose timestamp is t instead of the current time.
+// Only the time field is affected: entries are still appended to the file in
+// the order they are logged.
+func logAt(level zapcore.Level, t time.Time, msg string, keysAndValues []interface{}) {
+	ensureInitialized()
+
+	// Skip logAt and the exported wrapper
+	logger := globalLogger.Desugar().WithOptions(zap.AddCallerSkip(1))
+	if ce := logger.Check(level, msg); ce != nil {
+		ce.Time = t
+		ce.Write(toFields(keysAndValues)...)
+	}
+}
+
+// Logging functions with an explicit timestamp, e.g. for replaying historical events
+func InfoAt(t time.Time, msg string, keysAndValues ...interface{}) {
+	logAt(zap.InfoLevel, t, msg, keysAndValues)
+}
+
+func ErrorAt(t time.Time, msg string, keysAndValues ...interface{}) {
+	logAt(zap.ErrorLevel, t, msg, keysAndValues)
+}
+
+func WarnAt(t time.Time, msg string, keysAndValues ...interface{}) {
+	logAt(zap.WarnLevel, t, msg, keysAndValues)
+}
+
+func DebugAt(t time.Time, msg string, keysAndValues ...interface{}) {
+	logAt(zap.DebugLevel, t, msg, keysAndValues)
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceparentHeader is the W3C Trace Context request header
+const traceparentHeader = "traceparent"
+
+// ParseTraceparent extracts the trace ID and parent span ID of a W3C
+// traceparent header value, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". ok is false for
+// malformed values, the invalid version ff and all-zero IDs. Values of future
+// versions are accepted if they start with the version 00 fields.
+func ParseTraceparent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" {
+		return "", "", false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false
+	}
+	traceID, spanID = parts[1], parts[2]
+	if !isHex(traceID, 32) || !isHex(spanID, 16) || !isHex(parts[3], 2) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// Traceparent returns a W3C traceparent header value for the trace and span
+// of ctx, taken from its baggage when both IDs are in W3C format, otherwise
+// from its OTel span. It returns "" when ctx carries neither. Baggage IDs are
+// sent with the sampled flag unset since nothing is known about sampling.
+func Traceparent(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	b := BaggageFromContext(ctx)
+	if isHex(b.TraceID, 32) && isHex(b.SpanID, 16) {
+		return "00-" + b.TraceID + "-" + b.SpanID + "-00"
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-" + sc.TraceFlags().String()
+	}
+	return ""
+}
+
+// contextWithTraceparent starts a span of the trace in header for an incoming
+// request: the span ID of the header becomes parent_span_id and a new span_id
+// is created. A missing or malformed header starts a new trace unless ctx
+// already carries a trace ID; see newRequestTraceID for its format.
+func contextWithTraceparent(ctx context.Context, header string) context.Context {
+	traceID, parent, ok := ParseTraceparent(header)
+	if !ok {
+		if BaggageFromContext(ctx).TraceID != "" {
+			return ctx
+		}
+		traceID, parent = newRequestTraceID(), ""
+	}
+	return ContextWithBaggage(ctx, Baggage{TraceID: traceID, SpanID: newSpanID(), ParentSpanID: parent})
+}
+
+// newRequestTraceID returns the trace ID of a new request trace: from the
+// SetTraceIDGenerator generator or in Config.IDFormat when either is set, so
+// tests stay deterministic, and in W3C form otherwise. Only W3C IDs are
+// passed on in traceparent headers.
+func newRequestTraceID() string {
+	traceIDMu.RLock()
+	gen := traceIDGenerator
+	traceIDMu.RUnlock()
+	if gen == nil && globalConfig.IDFormat == "" {
+		return newW3CTraceID()
+	}
+	return generateTraceID(globalConfig.IDFormat)
+}
+
+// newW3CTraceID returns a random 128-bit trace ID in hex
+func newW3CTraceID() string {
+	return fmt.Sprintf("%016x%016x", rand.Uint64(), rand.Uint64())
+}
+
+// isHex reports whether s is n lowercase hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// truncateCore shortens oversized messages, and optionally string field
+// values, before a sink encodes them. It wraps each sink core so every entry
+// path is covered, including loggers derived with With and adapters.
+type truncateCore struct {
+	zapcore.Core
+	limit  int
+	fields bool // also truncate string field values
+}
+
+func (c *truncateCore) With(fields []zapcore.Field) zapcore.Core {
+	return &truncateCore{Core: c.Core.With(c.truncateFields(fields)), limit: c.limit, fields: c.fields}
+}
+
+func (c *truncateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *truncateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = truncateString(ent.Message, c.limit)
+	return c.Core.Write(ent, c.truncateFields(fields))
+}
+
+func (c *truncateCore) truncateFields(fields []zapcore.Field) []zapcore.Field {
+	if !c.fields {
+		return fields
+	}
+
+	var out []zapcore.Field
+	for i, f := range fields {
+		if f.Type != zapcore.StringType || len(f.String) <= c.limit {
+			continue
+		}
+		if out == nil {
+			// Copy on first change, the caller's slice must not be modified
+			out = append([]zapcore.Field(nil), fields...)
+		}
+		out[i].String = truncateString(f.String, c.limit)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// maxFieldsCore keeps the first limit fields of every entry and replaces the
+// rest with fields_truncated, the number dropped. Context fields are not
+// counted since they are attached once rather than per call, and the fields
+// the pipeline appends, seq, ttl_seconds and component, are always kept.
+type maxFieldsCore struct {
+	zapcore.Core
+	limit int
+}
+
+func (c *maxFieldsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &maxFieldsCore{Core: c.Core.With(fields), limit: c.limit}
+}
+
+func (c *maxFieldsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *maxFieldsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if len(fields) <= c.limit {
+		return c.Core.Write(ent, fields)
+	}
+	kept := make([]zapcore.Field, 0, c.limit+1)
+	var added []zapcore.Field
+	dropped := 0
+	for _, f := range fields {
+		switch {
+		case f.Key == seqKey || f.Key == ttlKey || f.Key == componentKey:
+			added = append(added, f)
+		case len(kept) < c.limit:
+			kept = append(kept, f)
+		default:
+			dropped++
+		}
+	}
+	if dropped == 0 {
+		return c.Core.Write(ent, fields)
+	}
+	kept = append(kept, zap.Int("fields_truncated", dropped))
+	return c.Core.Write(ent, append(kept, added...))
+}
+
+// truncateString cuts s to at most limit bytes on a rune boundary and appends
+// a marker with the number of bytes removed
+func truncateString(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:cut], len(s)-cut)
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMaxFieldsKeepsPipelineFields(t *testing.T) {
+	defer Snapshot()()
+	path := filepath.Join(t.TempDir(), "app.log")
+	err := Init(Config{
+		ServiceName:     "test",
+		LogFile:         path,
+		MaxFields:       2,
+		IncludeSequence: true,
+		DefaultTTL:      time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	InfoStruct("wide", "a", 1, "b", 2, "c", 3, "d", 4)
+	InfoStruct("narrow", "a", 1)
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("wrote %d entries, want 2: %v", len(entries), entries)
+	}
+	wide, narrow := entries[0], entries[1]
+	for _, key := range []string{"a", "b"} {
+		if _, ok := wide[key]; !ok {
+			t.Errorf("first field %s dropped: %v", key, wide)
+		}
+	}
+	for _, key := range []string{"c", "d"} {
+		if _, ok := wide[key]; ok {
+			t.Errorf("field %s beyond MaxFields kept: %v", key, wide)
+		}
+	}
+	if wide["fields_truncated"] != float64(2) {
+		t.Errorf("fields_truncated = %v, want 2", wide["fields_truncated"])
+	}
+	for i, e := range entries {
+		if e["seq"] != float64(i+1) {
+			t.Errorf("entry %d seq = %v, want %d", i, e["seq"], i+1)
+		}
+		if e["ttl_seconds"] != float64(60) {
+			t.Errorf("entry %d ttl_seconds = %v, want 60", i, e["ttl_seconds"])
+		}
+	}
+	if _, ok := narrow["fields_truncated"]; ok {
+		t.Errorf("entry within MaxFields marked truncated: %v", narrow)
+	}
+}
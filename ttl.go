@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ttlKey is the field read by the retention system
+const ttlKey = "ttl_seconds"
+
+// WithTTL returns a logger whose entries carry ttl_seconds, overriding
+// Config.DefaultTTL. Durations are truncated to whole seconds. A negative TTL
+// is invalid; it is reported on the internal logger and the default TTL is kept.
+func WithTTL(d time.Duration) *zap.SugaredLogger {
+	ensureInitialized()
+	// Returned for direct use, so drop the skip meant for the package functions
+	l := globalLogger.WithOptions(zap.AddCallerSkip(-1))
+	if d < 0 {
+		InternalLogger().Warnw("ignoring negative log TTL", "ttl", d.String())
+		return l
+	}
+	return l.With(ttlField(d))
+}
+
+func ttlField(d time.Duration) zap.Field {
+	return zap.Int64(ttlKey, int64(d/time.Second))
+}
+
+// ttlCore adds the default TTL to entries that don't set ttl_seconds
+// themselves, either through With or as an entry field. Like filterCore it
+// decides at write time, when the entry's fields are known.
+type ttlCore struct {
+	zapcore.Core
+	field zap.Field
+}
+
+func newTTLCore(core zapcore.Core, d time.Duration) zapcore.Core {
+	if d <= 0 {
+		return core
+	}
+	return &ttlCore{Core: core, field: ttlField(d)}
+}
+
+func (c *ttlCore) With(fields []zapcore.Field) zapcore.Core {
+	if hasField(fields, ttlKey) {
+		// Overridden for this logger, nothing left to add
+		return c.Core.With(fields)
+	}
+	return &ttlCore{Core: c.Core.With(fields), field: c.field}
+}
+
+func (c *ttlCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return deferredWrite(c.Core, ent, ce, c.addTTL)
+}
+
+// addTTL adds the default ttl_seconds unless the entry sets its own
+func (c *ttlCore) addTTL(_ zapcore.Entry, fields []zapcore.Field) ([]zapcore.Field, bool) {
+	if !hasField(fields, ttlKey) {
+		fields = append(fields[:len(fields):len(fields)], c.field)
+	}
+	return fields, true
+}
+
+func hasField(fields []zapcore.Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// usedLogger is set while the global logger was installed with Use
+var usedLogger bool
+
+// Use installs l as the global logger instead of building one with Init, so
+// the package functions and Ctx helpers log through cores the caller built.
+// Hooks, filters and package tags apply to it, writers registered with
+// AddWriter do not receive its entries. SetLevel can raise the level above
+// the lowest level l's core enables but not lower it further. Audit logs
+// through l as well.
+//
+// The caller keeps ownership of l and its sinks: Sync and Close flush l but
+// never close anything, and a later Init or Use replaces l without syncing it.
+func Use(l *zap.Logger) {
+	// Flush the previous logger like Init; it is not closed since derived loggers may still use it
+	if globalLogger != nil {
+		_ = globalLogger.Sync()
+	}
+
+	level := zap.NewAtomicLevelAt(zapcore.LevelOf(l.Core()))
+	base := l.WithOptions(
+		zap.AddCallerSkip(1),
+		zap.WrapCore(func(core zapcore.Core) zapcore.Core { return newLevelCore(core, level) }),
+	)
+
+	internal = capturable(base, nil, false).Sugar()
+	globalLogger = capturable(base.WithOptions(zap.WrapCore(registryCore)), nil, true).Sugar()
+	globalAudit = globalLogger
+	globalCritical = globalLogger
+	globalTraceless = nil
+	globalAccess = nil
+	globalStats = nil
+	globalSinks = nil
+	globalConfig = Config{}
+	globalLevel = level
+	globalCleanup = l.Sync
+	serviceLogger = nil
+	globalPipeline = nil
+	usedLogger = true
+	initialized = true
+	replayPreInit()
+}
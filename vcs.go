@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"runtime/debug"
+	"strconv"
+)
+
+// vcsFields returns the version control settings stamped into the binary by
+// go build: vcs_revision, vcs_time and vcs_modified. The Go toolchain records
+// no branch. Binaries built without VCS stamping, e.g. by go run in older
+// toolchains or with -buildvcs=false, yield none.
+func vcsFields() map[string]interface{} {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			fields["vcs_revision"] = s.Value
+		case "vcs.time":
+			fields["vcs_time"] = s.Value
+		case "vcs.modified":
+			modified, _ := strconv.ParseBool(s.Value)
+			fields["vcs_modified"] = modified
+		}
+	}
+	return fields
+}
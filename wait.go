@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WaitFor blocks until the global logger writes an entry matching predicate
+// and returns nil, or returns ctx.Err() when ctx is done first. Only entries
+// logged after the call are considered. It is meant for integration tests
+// synchronizing on output such as "server started"; the temporary hook is
+// removed before WaitFor returns.
+func WaitFor(ctx context.Context, predicate func(zapcore.Entry) bool) error {
+	ensureInitialized()
+
+	matched := make(chan struct{})
+	var once sync.Once
+	id := AddHook("WaitFor", func(ent zapcore.Entry) error {
+		if predicate(ent) {
+			once.Do(func() { close(matched) })
+		}
+		return nil
+	})
+	defer RemoveHook(id)
+
+	select {
+	case <-matched:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// watchdogSyncer times every write to the primary sink. A write taking longer
+// than the timeout is left to finish in the background and the sink is marked
+// degraded: until that write returns, entries go to the fallback file instead,
+// so a stalled destination doesn't block the application.
+type watchdogSyncer struct {
+	primary  zapcore.WriteSyncer
+	fallback zapcore.WriteSyncer
+	timeout  time.Duration
+	stats    *writeStats
+	path     string
+	fallPath string
+}
+
+type writeResult struct {
+	n   int
+	err error
+}
+
+func (w *watchdogSyncer) Write(p []byte) (int, error) {
+	if w.stats.degraded.Load() {
+		return w.fallback.Write(p)
+	}
+
+	// The caller may reuse p once Write returns, even if the write is still running
+	buf := append([]byte(nil), p...)
+	done := make(chan writeResult, 1)
+	go func() {
+		n, err := w.primary.Write(buf)
+		done <- writeResult{n, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-clock().After(w.timeout):
+		w.degrade(done)
+		return w.fallback.Write(p)
+	}
+}
+
+// degrade switches to the fallback until the stalled write completes
+func (w *watchdogSyncer) degrade(stalled <-chan writeResult) {
+	if w.stats.degraded.Swap(true) {
+		return
+	}
+	// Logged from another goroutine: this write may hold locks the logger needs.
+	// Called directly rather than through a package function, hence the skip.
+	meta := InternalLogger().WithOptions(zap.AddCallerSkip(-1))
+	go func() {
+		meta.Warnw("log sink is stalled, writing to the fallback file",
+			"log_file", w.path, "fallback_log_file", w.fallPath, "write_timeout", w.timeout.String())
+		<-stalled
+		w.stats.degraded.Store(false)
+		meta.Infow("log sink recovered", "log_file", w.path)
+	}()
+}
+
+func (w *watchdogSyncer) Sync() error {
+	err := w.fallback.Sync()
+	if !w.stats.degraded.Load() {
+		err = multierr.Append(err, w.primary.Sync())
+	}
+	return err
+}
+
+// SinkHealthy reports whether the global logger writes to LogFile, as opposed
+// to the fallback file used while LogFile is stalled, see Config.WriteTimeout
+func SinkHealthy() bool {
+	if globalStats == nil {
+		return true
+	}
+	return !globalStats.degraded.Load()
+}